@@ -0,0 +1,128 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+)
+
+// Discord is the original identity provider, moved here from routes_auth so
+// it implements the same AuthProvider contract as every other provider.
+type Discord struct{}
+
+func (Discord) Name() string { return "discord" }
+
+func (Discord) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	BeginOAuthRedirect(w, r, storage.OAuthConfig)
+}
+
+func (Discord) Callback(r *http.Request) (*ProviderIdentity, error) {
+	returnTo, err := ConsumeOAuthState(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oauth state: %w", err)
+	}
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		return nil, fmt.Errorf("missing code")
+	}
+
+	token, err := storage.OAuthConfig.Exchange(r.Context(), code)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+
+	user, err := fetchDiscordUser(r.Context(), token.AccessToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching user: %w", err)
+	}
+
+	// Mirror the identity into the provider-specific table so existing
+	// consumers of AuthenticationMethodDiscord (MeHandler, etc.) keep working.
+	var method models.AuthenticationMethodDiscord
+	err = storage.DB.Where("discord_id = ?", user.ID).First(&method).Error
+	if err == nil {
+		method.Username = user.Username
+		method.Discriminator = user.Discriminator
+		method.Email = user.Email
+		method.AvatarURL = user.Avatar
+		storage.DB.Save(&method)
+	}
+
+	return &ProviderIdentity{
+		ProviderUserID: user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		AvatarURL:      user.Avatar,
+		ReturnTo:       returnTo,
+	}, nil
+}
+
+func (Discord) Unlink(userID uint) error {
+	return storage.DB.Where("user_id = ?", userID).Delete(&models.AuthenticationMethodDiscord{}).Error
+}
+
+// FindLinkedUser returns the user already linked to this Discord account, if any.
+func (Discord) FindLinkedUser(identity *ProviderIdentity) (*models.User, error) {
+	var method models.AuthenticationMethodDiscord
+	if err := storage.DB.Where("discord_id = ?", identity.ProviderUserID).First(&method).Error; err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := storage.DB.First(&user, method.UserID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// LinkIdentity persists the AuthenticationMethodDiscord row for a user,
+// separately from login so /me/links/discord can attach a second identity
+// without minting a session.
+func (Discord) LinkIdentity(userID uint, identity *ProviderIdentity) error {
+	var method models.AuthenticationMethodDiscord
+	err := storage.DB.Where("discord_id = ?", identity.ProviderUserID).First(&method).Error
+	if err == nil {
+		return fmt.Errorf("this discord account is already linked to a user")
+	}
+	method = models.AuthenticationMethodDiscord{
+		UserID:        userID,
+		DiscordID:     identity.ProviderUserID,
+		Username:      identity.Username,
+		Email:         identity.Email,
+		AvatarURL:     identity.AvatarURL,
+		Discriminator: "0",
+	}
+	return storage.DB.Create(&method).Error
+}
+
+// ---------- Discord API fetch ----------
+type discordUserResponse struct {
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Discriminator string `json:"discriminator"`
+	Email         string `json:"email"`
+	Avatar        string `json:"avatar"`
+	Verified      bool   `json:"verified"`
+}
+
+func fetchDiscordUser(ctx context.Context, accessToken string) (*discordUserResponse, error) {
+	req, _ := http.NewRequestWithContext(ctx, "GET", "https://discord.com/api/users/@me", nil)
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discord returned status %d", res.StatusCode)
+	}
+	var u discordUserResponse
+	if err := json.NewDecoder(res.Body).Decode(&u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
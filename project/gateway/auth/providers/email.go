@@ -0,0 +1,161 @@
+package providers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Email is the only non-redirect-based provider: BeginLogin registers a new
+// account and emails a verification code (logged here, since there's no mail
+// sender wired up yet); Callback checks POSTed credentials against the
+// stored hash.
+type Email struct{}
+
+func (Email) Name() string { return "email" }
+
+type emailSignupRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// BeginLogin doubles as the signup endpoint for this provider: POST
+// {email,password} to create a pending, unverified account.
+func (Email) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	var req emailSignupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" || len(req.Password) < 8 {
+		http.Error(w, "email and a password of at least 8 characters are required", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "failed to hash password", http.StatusInternalServerError)
+		return
+	}
+
+	method := models.AuthenticationMethodEmail{
+		Email:            req.Email,
+		PasswordHash:     string(hash),
+		VerificationCode: generateVerificationCode(),
+	}
+	if err := storage.DB.Create(&method).Error; err != nil {
+		http.Error(w, "email already registered", http.StatusConflict)
+		return
+	}
+
+	// TODO: wire up a real mail sender; log it for now so local dev can verify.
+	storage.Logger.Info("email verification code", "email", req.Email, "code", method.VerificationCode)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Callback checks POSTed {email,password} against the stored hash and
+// requires the address to have been verified.
+func (Email) Callback(r *http.Request) (*ProviderIdentity, error) {
+	var req emailSignupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, fmt.Errorf("invalid request body")
+	}
+
+	var method models.AuthenticationMethodEmail
+	if err := storage.DB.Where("email = ?", req.Email).First(&method).Error; err != nil {
+		return nil, fmt.Errorf("invalid email or password")
+	}
+	if method.VerifiedAt == nil {
+		return nil, fmt.Errorf("email not yet verified")
+	}
+	if bcrypt.CompareHashAndPassword([]byte(method.PasswordHash), []byte(req.Password)) != nil {
+		return nil, fmt.Errorf("invalid email or password")
+	}
+
+	return &ProviderIdentity{
+		ProviderUserID: fmt.Sprintf("%d", method.ID),
+		Username:       req.Email,
+		Email:          req.Email,
+	}, nil
+}
+
+func (Email) Unlink(userID uint) error {
+	return storage.DB.Where("user_id = ?", userID).Delete(&models.AuthenticationMethodEmail{}).Error
+}
+
+func (Email) FindLinkedUser(identity *ProviderIdentity) (*models.User, error) {
+	var method models.AuthenticationMethodEmail
+	if err := storage.DB.Where("email = ?", identity.Email).First(&method).Error; err != nil {
+		return nil, err
+	}
+	if method.UserID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	var user models.User
+	if err := storage.DB.First(&user, method.UserID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// LinkIdentity attaches a verified, not-yet-linked email signup to an
+// existing user.
+func (Email) LinkIdentity(userID uint, identity *ProviderIdentity) error {
+	var method models.AuthenticationMethodEmail
+	if err := storage.DB.Where("email = ?", identity.Email).First(&method).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("no verified signup found for that email")
+		}
+		return err
+	}
+	if method.VerifiedAt == nil {
+		return fmt.Errorf("no verified signup found for that email")
+	}
+	if method.UserID != 0 && method.UserID != userID {
+		return fmt.Errorf("this email is already linked to a user")
+	}
+	method.UserID = userID
+	return storage.DB.Save(&method).Error
+}
+
+// VerifyEmail marks a pending signup as verified given its verification code.
+func VerifyEmail(email, code string) error {
+	var method models.AuthenticationMethodEmail
+	if err := storage.DB.Where("email = ? AND verification_code = ?", email, code).First(&method).Error; err != nil {
+		return fmt.Errorf("invalid verification code")
+	}
+	now := time.Now()
+	return storage.DB.Model(&method).Update("verified_at", &now).Error
+}
+
+type verifyEmailRequest struct {
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+// VerifyEmailHandler is the HTTP handler behind POST /auth/email/verify: it
+// consumes the verification code BeginLogin generated, without which
+// Callback's VerifiedAt check rejects the account forever.
+func VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req verifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" || req.Code == "" {
+		http.Error(w, "email and code are required", http.StatusBadRequest)
+		return
+	}
+	if err := VerifyEmail(req.Email, req.Code); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func generateVerificationCode() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
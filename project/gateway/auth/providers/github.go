@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"strconv"
+)
+
+// GitHub authenticates against the GitHub REST API. Only registered when
+// GITHUB_CLIENT_ID/SECRET are set.
+type GitHub struct{}
+
+func (GitHub) Name() string { return "github" }
+
+func (GitHub) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	BeginOAuthRedirect(w, r, storage.GitHubOAuthConfig)
+}
+
+type githubUserResponse struct {
+	ID        int    `json:"id"`
+	Login     string `json:"login"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+func (GitHub) Callback(r *http.Request) (*ProviderIdentity, error) {
+	returnTo, err := ConsumeOAuthState(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oauth state: %w", err)
+	}
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		return nil, fmt.Errorf("missing code")
+	}
+
+	token, err := storage.GitHubOAuthConfig.Exchange(r.Context(), code)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+
+	req, _ := http.NewRequestWithContext(r.Context(), "GET", "https://api.github.com/user", nil)
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching user: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("github returned status %d", res.StatusCode)
+	}
+
+	var u githubUserResponse
+	if err := json.NewDecoder(res.Body).Decode(&u); err != nil {
+		return nil, err
+	}
+
+	return &ProviderIdentity{
+		ProviderUserID: strconv.Itoa(u.ID),
+		Username:       u.Login,
+		Email:          u.Email,
+		AvatarURL:      u.AvatarURL,
+		ReturnTo:       returnTo,
+	}, nil
+}
+
+func (GitHub) Unlink(userID uint) error {
+	return storage.DB.Where("user_id = ?", userID).Delete(&models.AuthenticationMethodGitHub{}).Error
+}
+
+func (GitHub) FindLinkedUser(identity *ProviderIdentity) (*models.User, error) {
+	var method models.AuthenticationMethodGitHub
+	if err := storage.DB.Where("github_id = ?", identity.ProviderUserID).First(&method).Error; err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := storage.DB.First(&user, method.UserID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (GitHub) LinkIdentity(userID uint, identity *ProviderIdentity) error {
+	var method models.AuthenticationMethodGitHub
+	err := storage.DB.Where("github_id = ?", identity.ProviderUserID).First(&method).Error
+	if err == nil {
+		return fmt.Errorf("this github account is already linked to a user")
+	}
+	method = models.AuthenticationMethodGitHub{
+		UserID:    userID,
+		GitHubID:  identity.ProviderUserID,
+		Username:  identity.Username,
+		Email:     identity.Email,
+		AvatarURL: identity.AvatarURL,
+	}
+	return storage.DB.Create(&method).Error
+}
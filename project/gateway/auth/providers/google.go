@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+)
+
+// Google authenticates against Google's OpenID Connect userinfo endpoint.
+// Only registered when GOOGLE_CLIENT_ID/SECRET are set.
+type Google struct{}
+
+func (Google) Name() string { return "google" }
+
+func (Google) BeginLogin(w http.ResponseWriter, r *http.Request) {
+	BeginOAuthRedirect(w, r, storage.GoogleOAuthConfig)
+}
+
+type googleUserResponse struct {
+	Sub     string `json:"sub"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Picture string `json:"picture"`
+}
+
+func (Google) Callback(r *http.Request) (*ProviderIdentity, error) {
+	returnTo, err := ConsumeOAuthState(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oauth state: %w", err)
+	}
+
+	code := r.URL.Query().Get("code")
+	if code == "" {
+		return nil, fmt.Errorf("missing code")
+	}
+
+	token, err := storage.GoogleOAuthConfig.Exchange(r.Context(), code)
+	if err != nil {
+		return nil, fmt.Errorf("token exchange failed: %w", err)
+	}
+
+	req, _ := http.NewRequestWithContext(r.Context(), "GET", "https://openidconnect.googleapis.com/v1/userinfo", nil)
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching user: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("google returned status %d", res.StatusCode)
+	}
+
+	var u googleUserResponse
+	if err := json.NewDecoder(res.Body).Decode(&u); err != nil {
+		return nil, err
+	}
+
+	return &ProviderIdentity{
+		ProviderUserID: u.Sub,
+		Username:       u.Name,
+		Email:          u.Email,
+		AvatarURL:      u.Picture,
+		ReturnTo:       returnTo,
+	}, nil
+}
+
+func (Google) Unlink(userID uint) error {
+	return storage.DB.Where("user_id = ?", userID).Delete(&models.AuthenticationMethodGoogle{}).Error
+}
+
+func (Google) FindLinkedUser(identity *ProviderIdentity) (*models.User, error) {
+	var method models.AuthenticationMethodGoogle
+	if err := storage.DB.Where("google_id = ?", identity.ProviderUserID).First(&method).Error; err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := storage.DB.First(&user, method.UserID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (Google) LinkIdentity(userID uint, identity *ProviderIdentity) error {
+	var method models.AuthenticationMethodGoogle
+	err := storage.DB.Where("google_id = ?", identity.ProviderUserID).First(&method).Error
+	if err == nil {
+		return fmt.Errorf("this google account is already linked to a user")
+	}
+	method = models.AuthenticationMethodGoogle{
+		UserID:    userID,
+		GoogleID:  identity.ProviderUserID,
+		Username:  identity.Username,
+		Email:     identity.Email,
+		AvatarURL: identity.AvatarURL,
+	}
+	return storage.DB.Create(&method).Error
+}
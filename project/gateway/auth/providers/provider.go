@@ -0,0 +1,71 @@
+// Package providers defines the pluggable identity provider contract used
+// by the generic /auth/{provider}/login and /auth/{provider}/callback
+// routes, plus the account-linking endpoints under /me/links.
+package providers
+
+import (
+	"gateway/models"
+	"net/http"
+)
+
+// ProviderIdentity is the identity a provider's Callback resolves, normalized
+// away from whatever field names the upstream API actually uses.
+type ProviderIdentity struct {
+	ProviderUserID string
+	Username       string
+	Email          string
+	AvatarURL      string
+	// ReturnTo is set by redirect-based providers from the OAuth state they
+	// verified in Callback, so the generic callback handler can redirect to
+	// a per-client destination instead of one hard-coded URL.
+	ReturnTo string
+}
+
+// AuthProvider is an identity provider the gateway can authenticate a player
+// against, either to log them in for the first time or to link a second
+// identity to an already-logged-in user's account.
+type AuthProvider interface {
+	// Name is the registry key and the {provider} path segment, e.g. "discord".
+	Name() string
+	// BeginLogin starts the provider's login flow, typically a redirect.
+	BeginLogin(w http.ResponseWriter, r *http.Request)
+	// Callback resolves the identity asserted by this request, once the
+	// provider's flow has redirected (or posted) back to the gateway.
+	Callback(r *http.Request) (*ProviderIdentity, error)
+	// Unlink removes this provider's stored identity for the given user.
+	Unlink(userID uint) error
+}
+
+// IdentityLinker is implemented by every provider so the login and
+// account-linking handlers can find or attach a user without switching on
+// provider name. It's kept separate from AuthProvider because the two
+// operations (log in as whoever this identity maps to vs. attach this
+// identity to an already-authenticated user) have different callers.
+type IdentityLinker interface {
+	// FindLinkedUser returns the user this identity is already linked to,
+	// or gorm.ErrRecordNotFound if it has never been seen before.
+	FindLinkedUser(identity *ProviderIdentity) (*models.User, error)
+	// LinkIdentity attaches this identity to userID. It errors if the
+	// identity is already linked to a different user.
+	LinkIdentity(userID uint, identity *ProviderIdentity) error
+}
+
+var registry = map[string]AuthProvider{}
+
+// Register adds a provider to the registry keyed by its Name(), so routes
+// are generated uniformly rather than switching on provider name.
+func Register(p AuthProvider) {
+	registry[p.Name()] = p
+}
+
+// Get looks up a registered provider by name.
+func Get(name string) (AuthProvider, bool) {
+	p, ok := registry[name]
+	return p, ok
+}
+
+// All returns every registered provider, for handlers that need to iterate
+// them (e.g. listing a user's linked methods).
+func All() map[string]AuthProvider {
+	return registry
+}
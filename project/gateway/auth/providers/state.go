@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"gateway/storage"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	oauthStateCookie = "oauth_state"
+	oauthStateTTL    = 10 * time.Minute
+)
+
+type oauthStateRecord struct {
+	State    string `json:"state"`
+	ReturnTo string `json:"return_to"`
+}
+
+func generateSecureState() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "fallback-state"
+	}
+	return fmt.Sprintf("%x", b)
+}
+
+// BeginOAuthRedirect starts a redirect-based provider's login flow: it
+// stores a random state (plus the caller's return_to) in the Redis cache
+// keyed by a second random id, sets that id as an HttpOnly; SameSite=Lax
+// cookie, and redirects into the provider's consent screen. The cookie and
+// the cached record are what ConsumeOAuthState (called from the provider's
+// Callback) checks against each other, so a forged callback can't reuse a leaked state value
+// without also holding the victim's browser cookie.
+func BeginOAuthRedirect(w http.ResponseWriter, r *http.Request, config *oauth2.Config) {
+	stateID := generateSecureState()
+	record := oauthStateRecord{
+		State:    generateSecureState(),
+		ReturnTo: r.URL.Query().Get("return_to"),
+	}
+
+	payload, err := json.Marshal(record)
+	if err != nil {
+		http.Error(w, "failed to start login", http.StatusInternalServerError)
+		return
+	}
+	if err := storage.Cache.Set(r.Context(), "oauth_state:"+stateID, payload, oauthStateTTL).Err(); err != nil {
+		http.Error(w, "failed to start login", http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    stateID,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(oauthStateTTL.Seconds()),
+	})
+
+	http.Redirect(w, r, config.AuthCodeURL(record.State, oauth2.AccessTypeOffline), http.StatusFound)
+}
+
+// ConsumeOAuthState validates the callback's "state" query parameter against
+// the oauth_state cookie's cached record, atomically deleting the record
+// (via GETDEL) so it can't be replayed, and returns the return_to URL the
+// login began with.
+func ConsumeOAuthState(r *http.Request) (returnTo string, err error) {
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil {
+		return "", fmt.Errorf("missing oauth_state cookie")
+	}
+	queryState := r.URL.Query().Get("state")
+	if queryState == "" {
+		return "", fmt.Errorf("missing state parameter")
+	}
+
+	payload, err := storage.Cache.GetDel(r.Context(), "oauth_state:"+cookie.Value).Result()
+	if err != nil {
+		return "", fmt.Errorf("state not found or expired")
+	}
+
+	var record oauthStateRecord
+	if err := json.Unmarshal([]byte(payload), &record); err != nil {
+		return "", fmt.Errorf("corrupt state record")
+	}
+	if record.State != queryState {
+		return "", fmt.Errorf("state mismatch")
+	}
+	return record.ReturnTo, nil
+}
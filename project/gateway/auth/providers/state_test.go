@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"encoding/json"
+	"gateway/storage"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/oauth2"
+)
+
+func discordTestConfig() *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		RedirectURL:  "https://gateway.example/auth/discord/callback",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://discord.com/api/oauth2/authorize",
+			TokenURL: "https://discord.com/api/oauth2/token",
+		},
+	}
+}
+
+func setupTestCache(t *testing.T) {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	t.Cleanup(mr.Close)
+	storage.Cache = redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestConsumeOAuthStateRoundTrip(t *testing.T) {
+	setupTestCache(t)
+
+	rec := httptest.NewRecorder()
+	begin := httptest.NewRequest(http.MethodGet, "/auth/discord?return_to=https://client.example/landing", nil)
+	BeginOAuthRedirect(rec, begin, discordTestConfig())
+
+	stateCookie := rec.Result().Cookies()[0]
+	if stateCookie.Name != oauthStateCookie {
+		t.Fatalf("expected %s cookie, got %s", oauthStateCookie, stateCookie.Name)
+	}
+
+	raw, err := storage.Cache.Get(begin.Context(), "oauth_state:"+stateCookie.Value).Result()
+	if err != nil {
+		t.Fatalf("expected state to be cached: %v", err)
+	}
+	var record oauthStateRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		t.Fatalf("unmarshal cached state: %v", err)
+	}
+
+	callback := httptest.NewRequest(http.MethodGet, "/auth/discord/callback?state="+record.State, nil)
+	callback.AddCookie(stateCookie)
+
+	returnTo, err := ConsumeOAuthState(callback)
+	if err != nil {
+		t.Fatalf("expected valid state to be consumed, got: %v", err)
+	}
+	if returnTo != "https://client.example/landing" {
+		t.Fatalf("expected return_to to round-trip, got %q", returnTo)
+	}
+
+	if _, err := ConsumeOAuthState(callback); err == nil {
+		t.Fatal("expected a replayed state to be rejected")
+	}
+}
+
+func TestConsumeOAuthStateRejectsMismatch(t *testing.T) {
+	setupTestCache(t)
+
+	rec := httptest.NewRecorder()
+	begin := httptest.NewRequest(http.MethodGet, "/auth/discord", nil)
+	BeginOAuthRedirect(rec, begin, discordTestConfig())
+	stateCookie := rec.Result().Cookies()[0]
+
+	callback := httptest.NewRequest(http.MethodGet, "/auth/discord/callback?state=forged-state", nil)
+	callback.AddCookie(stateCookie)
+
+	if _, err := ConsumeOAuthState(callback); err == nil {
+		t.Fatal("expected a forged state parameter to be rejected")
+	}
+}
@@ -2,21 +2,29 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"gateway/auth/providers"
 	"gateway/middlewares"
 	routes_index "gateway/routes"
+	routes_admin "gateway/routes/admin"
 	routes_auth "gateway/routes/auth"
 	routes_client "gateway/routes/client"
+	routes_realm "gateway/routes/realm"
 	"gateway/storage"
-	"log"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 func main() {
+	storage.InitLogger()
+
 	// Load .env file first
 	if err := godotenv.Load(); err != nil {
-		log.Println("⚠️  No .env file found, falling back to system environment variables")
+		storage.Logger.Warn("no .env file found, falling back to system environment variables")
 	}
 
 	// Initialize configuration
@@ -28,14 +36,71 @@ func main() {
 	// Initialize Cache
 	storage.InitCache(storage.CacheDSN)
 
+	// Grant the "admin" role to any ADMIN_DISCORD_IDS operators so the first
+	// one can reach /admin without direct DB access.
+	storage.SeedAdminRoles()
+
+	// Register identity providers. Google and GitHub only activate if their
+	// env vars were set; Email always registers since it needs no third party.
+	providers.Register(providers.Discord{})
+	providers.Register(providers.Email{})
+	if storage.GoogleOAuthConfig != nil {
+		providers.Register(providers.Google{})
+	}
+	if storage.GitHubOAuthConfig != nil {
+		providers.Register(providers.GitHub{})
+	}
+
 	http.HandleFunc("/", routes_index.IndexHandler)
-	http.HandleFunc("/login", routes_auth.LoginHandler)
-	http.HandleFunc("/auth/discord/callback", routes_auth.AuthCallbackHandler)
+	for name, provider := range providers.All() {
+		http.HandleFunc(fmt.Sprintf("/auth/%s/login", name), routes_auth.LoginHandler(provider))
+		http.HandleFunc(fmt.Sprintf("/auth/%s/callback", name), routes_auth.CallbackHandler(provider))
+	}
+	http.HandleFunc("POST /me/links/{provider}", middlewares.AuthMiddleware(routes_client.LinkHandler))
+	http.HandleFunc("DELETE /me/links/{provider}", middlewares.AuthMiddleware(routes_client.UnlinkHandler))
+	http.HandleFunc("POST /signup/complete", routes_auth.SignupCompleteHandler)
+	http.HandleFunc("POST /auth/email/verify", providers.VerifyEmailHandler)
+	http.HandleFunc("POST /auth/refresh", routes_auth.RefreshHandler)
 	http.HandleFunc("/me", middlewares.AuthMiddleware(routes_client.MeHandler))
-	http.HandleFunc("/client/realmlist", middlewares.AuthMiddleware(routes_client.RealmListHandler))
-	http.HandleFunc("/client/characterslist", middlewares.AuthMiddleware(routes_client.CharacterListHandler))
+	http.HandleFunc("GET /me/sessions", middlewares.AuthMiddleware(routes_client.ListSessionsHandler))
+	http.HandleFunc("DELETE /me/sessions/{id}", middlewares.AuthMiddleware(routes_client.RevokeSessionHandler))
+	http.HandleFunc("/client/realmlist", middlewares.AuthMiddleware(middlewares.RequireScope("realm.read")(routes_client.RealmListHandler)))
+	http.HandleFunc("/client/characterslist", middlewares.AuthMiddleware(middlewares.RequireScope("character.read")(routes_client.CharacterListHandler)))
+
+	// OIDC discovery + JWKS, so realm/world servers can verify gateway-issued
+	// tokens without sharing storage.JwtSecret
+	http.HandleFunc("/.well-known/openid-configuration", routes_auth.DiscoveryHandler)
+	http.HandleFunc("/.well-known/jwks.json", routes_auth.JWKSHandler)
+
+	// OAuth2 endpoints for realm/world servers registered as confidential clients
+	http.HandleFunc("/oauth/authorize", middlewares.AuthMiddleware(routes_auth.AuthorizeHandler))
+	http.HandleFunc("/oauth/token", routes_auth.TokenHandler)
+	http.HandleFunc("/oauth/userinfo", routes_auth.UserinfoHandler)
+	http.HandleFunc("/oauth/revoke", routes_auth.RevokeHandler)
+
+	// Realm-facing registry subsystem. Registration and the admin endpoints
+	// now require the "admin" role rather than just being logged in.
+	http.HandleFunc("POST /realm/register", middlewares.AuthMiddleware(middlewares.RequireRole("admin")(routes_realm.RegisterHandler)))
+	http.HandleFunc("POST /realm/heartbeat", routes_realm.HeartbeatHandler)
+	http.HandleFunc("DELETE /realm/deregister", routes_realm.DeregisterHandler)
+	http.HandleFunc("GET /admin/realms", middlewares.AuthMiddleware(middlewares.RequireRole("admin")(routes_realm.AdminListHandler)))
+	http.HandleFunc("POST /admin/realms/{id}/disable", middlewares.AuthMiddleware(middlewares.RequireRole("admin")(routes_realm.AdminDisableHandler)))
+
+	// Operator tooling for user accounts and active sessions.
+	http.HandleFunc("GET /admin/users", middlewares.AuthMiddleware(middlewares.RequireRole("admin")(routes_admin.ListUsersHandler)))
+	http.HandleFunc("POST /admin/users", middlewares.AuthMiddleware(middlewares.RequireRole("admin")(routes_admin.CreateUserHandler)))
+	http.HandleFunc("DELETE /admin/users", middlewares.AuthMiddleware(middlewares.RequireRole("admin")(routes_admin.DeleteUserHandler)))
+	http.HandleFunc("POST /admin/users/{id}/link-discord", middlewares.AuthMiddleware(middlewares.RequireRole("admin")(routes_admin.LinkDiscordHandler)))
+	http.HandleFunc("GET /admin/sessions", middlewares.AuthMiddleware(middlewares.RequireRole("admin")(routes_admin.ListSessionsHandler)))
+
+	cleanupCtx, stopCleanup := context.WithCancel(context.Background())
+	defer stopCleanup()
+	go routes_auth.CleanupExpiredPendingSignups(cleanupCtx, 5*time.Minute)
 
 	addr := ":8080"
-	log.Printf("listening on http://localhost%s", addr)
-	log.Fatal(http.ListenAndServe(addr, nil))
+	storage.Logger.Info("listening", "addr", addr)
+	if err := http.ListenAndServe(addr, middlewares.RequestLogger(http.DefaultServeMux)); err != nil {
+		storage.Logger.Error("server stopped", "err", err)
+		os.Exit(1)
+	}
 }
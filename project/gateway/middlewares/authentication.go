@@ -3,8 +3,10 @@ package middlewares
 import (
 	"context"
 	"fmt"
+	"gateway/models"
 	"gateway/storage"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -14,15 +16,34 @@ import (
 type ContextKey string
 
 const SessionIDKey ContextKey = "sessionID"
+const UserIDKey ContextKey = "userID"
+
+// ScopeKey holds the "scope" claim of a client-credential token
+// AuthMiddleware validated, if any. Its presence in context means the
+// caller authenticated as a scoped client (e.g. a realm/world server)
+// rather than a logged-in player, which is what RequireRole and
+// RequireScope key off of.
+const ScopeKey ContextKey = "scope"
 
 // ---------- JWT Handling ----------
-func parseJWT(tokenString string) (jwt.MapClaims, error) {
+
+// ParseAndVerifyJWT verifies an RS256 token against the rotating signing key
+// set: the token's "kid" header picks which key's public half to check it
+// against, so retired keys keep verifying tokens issued before rotation.
+func ParseAndVerifyJWT(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		// verify signing method
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-		return storage.JwtSecret, nil
+		kid, ok := t.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing kid header")
+		}
+		signingKey, err := storage.SigningKeyByKid(kid)
+		if err != nil {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		return storage.ParseRSAPublicKey(signingKey)
 	})
 	if err != nil || !token.Valid {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -67,21 +88,115 @@ func AuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		// parse and validate JWT
-		claims, err := parseJWT(tokenString)
+		claims, err := ParseAndVerifyJWT(tokenString)
 		if err != nil {
 			http.Error(w, "invalid session: "+err.Error(), http.StatusUnauthorized)
 			return
 		}
 
-		// get session ID from claims
-		sessionID, ok := claims["sid"].(string)
-		if !ok {
+		// get session ID from claims. Scoped client tokens minted by
+		// createScopedJWT (routes/auth/oauth.go's authorization_code and
+		// refresh_token grants, e.g. for a realm/world server) have no session to check — they
+		// carry a "scope" claim instead, and RequireScope is what gates
+		// those. A token with neither is rejected.
+		sessionID, hasSession := claims["sid"].(string)
+		scope, hasScope := claims["scope"].(string)
+
+		ctx := r.Context()
+		switch {
+		case hasSession:
+			// Reject tokens whose session was revoked (e.g. via DELETE
+			// /me/sessions/{id}), even though the JWT itself hasn't expired yet.
+			var session models.UserAuthenticatedSession
+			if err := storage.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+				http.Error(w, "session not found", http.StatusUnauthorized)
+				return
+			}
+			if session.RevokedAt != nil {
+				http.Error(w, "session revoked", http.StatusUnauthorized)
+				return
+			}
+			ctx = context.WithValue(ctx, SessionIDKey, sessionID)
+			ctx = context.WithValue(ctx, UserIDKey, session.UserID)
+			ctx = annotateSession(ctx, sessionID, session.UserID)
+		case hasScope && scope != "":
+			sub, ok := claims["sub"].(float64)
+			if !ok {
+				http.Error(w, "invalid sub claim", http.StatusUnauthorized)
+				return
+			}
+			ctx = context.WithValue(ctx, UserIDKey, uint(sub))
+			ctx = context.WithValue(ctx, ScopeKey, scope)
+		default:
 			http.Error(w, "invalid sid claim", http.StatusUnauthorized)
 			return
 		}
 
-		// Attach user id to context
-		ctx := context.WithValue(r.Context(), SessionIDKey, sessionID)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
+
+// RequireRole gates a route on the caller holding at least one of the given
+// roles. It reads the user id AuthMiddleware already attached to the
+// context, so it must be composed inside AuthMiddleware rather than used on
+// its own: middlewares.AuthMiddleware(middlewares.RequireRole("admin")(handler)).
+// Role-gated routes are for logged-in players only: a scoped client-credential
+// token (ScopeKey present) is rejected outright, even if its subject happens
+// to hold the role in the DB, since scope — not DB role membership — is what
+// bounds a client's access.
+func RequireRole(roles ...string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if _, scoped := r.Context().Value(ScopeKey).(string); scoped {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			userID, ok := r.Context().Value(UserIDKey).(uint)
+			if !ok {
+				http.Error(w, "unauthenticated", http.StatusUnauthorized)
+				return
+			}
+
+			var count int64
+			err := storage.DB.Table("user_roles").
+				Joins("JOIN roles ON roles.id = user_roles.role_id").
+				Where("user_roles.user_id = ? AND roles.name IN ?", userID, roles).
+				Count(&count).Error
+			if err != nil {
+				http.Error(w, "role lookup failed: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if count == 0 {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// RequireScope gates a route on the OAuth "scope" claim AuthMiddleware
+// resolved from whichever token it actually validated (cookie or bearer
+// header), for routes called by confidential clients (realm/world servers)
+// rather than a logged-in player. It must be composed inside AuthMiddleware,
+// like RequireRole. Tokens without a scope claim — i.e. ordinary player
+// session JWTs — are let through unchanged, since scope gating only applies
+// to client-credentialed access.
+func RequireScope(scope string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			grantedScope, hasScope := r.Context().Value(ScopeKey).(string)
+			if !hasScope {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !strings.Contains(" "+grantedScope+" ", " "+scope+" ") {
+				http.Error(w, "insufficient scope", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package middlewares
+
+import (
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupTestDB points storage.DB at a fresh in-memory sqlite database with
+// just the tables this file's tests touch, so these tests don't need a real
+// Postgres instance.
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.SigningKey{},
+		&models.UserAuthenticatedSession{},
+		&models.Role{},
+		&models.UserRole{},
+	); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	storage.DB = db
+}
+
+func signTestToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	signingKey, err := storage.ActiveSigningKey()
+	if err != nil {
+		t.Fatalf("active signing key: %v", err)
+	}
+	privateKey, err := storage.ParseRSAPrivateKey(signingKey)
+	if err != nil {
+		t.Fatalf("parse private key: %v", err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.Kid
+	signed, err := token.SignedString(privateKey)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestParseAndVerifyJWT(t *testing.T) {
+	setupTestDB(t)
+
+	valid := signTestToken(t, jwt.MapClaims{
+		"sub": float64(1),
+		"sid": uuid.New().String(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	if _, err := ParseAndVerifyJWT(valid); err != nil {
+		t.Fatalf("expected valid token to verify, got: %v", err)
+	}
+
+	expired := signTestToken(t, jwt.MapClaims{
+		"sub": float64(1),
+		"sid": uuid.New().String(),
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	if _, err := ParseAndVerifyJWT(expired); err == nil {
+		t.Fatal("expected expired token to be rejected")
+	}
+
+	unknownKid := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": float64(1),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	unknownKid.Header["kid"] = "does-not-exist"
+	signingKey, _ := storage.ActiveSigningKey()
+	privateKey, _ := storage.ParseRSAPrivateKey(signingKey)
+	signed, err := unknownKid.SignedString(privateKey)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	if _, err := ParseAndVerifyJWT(signed); err == nil {
+		t.Fatal("expected unknown kid to be rejected")
+	}
+
+	hsToken := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": float64(1),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	hsSigned, err := hsToken.SignedString([]byte("not-the-rsa-key"))
+	if err != nil {
+		t.Fatalf("sign HS256 token: %v", err)
+	}
+	if _, err := ParseAndVerifyJWT(hsSigned); err == nil {
+		t.Fatal("expected non-RSA alg to be rejected")
+	}
+}
+
+func TestAuthMiddlewareScopedTokenCannotReachRequireRole(t *testing.T) {
+	setupTestDB(t)
+
+	admin := models.Role{Name: "admin"}
+	if err := storage.DB.Create(&admin).Error; err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	if err := storage.DB.Create(&models.UserRole{UserID: 1, RoleID: admin.ID}).Error; err != nil {
+		t.Fatalf("grant role: %v", err)
+	}
+
+	scoped := signTestToken(t, jwt.MapClaims{
+		"sub":   float64(1),
+		"scope": "realm.read",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	handler := AuthMiddleware(RequireRole("admin")(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+	req.Header.Set("Authorization", "Bearer "+scoped)
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected a scoped token to be rejected by RequireRole, got status %d", rec.Code)
+	}
+}
+
+func TestRequireScopeEnforcesCookieDeliveredToken(t *testing.T) {
+	setupTestDB(t)
+
+	scoped := signTestToken(t, jwt.MapClaims{
+		"sub":   float64(1),
+		"scope": "realm.read",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	handler := AuthMiddleware(RequireScope("realm.write")(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/realm/heartbeat", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: scoped})
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected insufficient scope to be rejected even when the token arrived via cookie, got status %d", rec.Code)
+	}
+}
+
+func TestRequireScopeAllowsGrantedScope(t *testing.T) {
+	setupTestDB(t)
+
+	scoped := signTestToken(t, jwt.MapClaims{
+		"sub":   float64(1),
+		"scope": "realm.read realm.write",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+
+	handler := AuthMiddleware(RequireScope("realm.write")(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/realm/heartbeat", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: scoped})
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected granted scope to pass, got status %d", rec.Code)
+	}
+}
+
+func TestRequireScopeLetsPlayerSessionsThrough(t *testing.T) {
+	setupTestDB(t)
+
+	session := models.UserAuthenticatedSession{
+		ID:           uuid.New(),
+		UserID:       1,
+		LastActiveAt: time.Now(),
+		CreatedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	if err := storage.DB.Create(&session).Error; err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	playerToken := signTestToken(t, jwt.MapClaims{
+		"sub": float64(1),
+		"sid": session.ID.String(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+
+	handler := AuthMiddleware(RequireScope("realm.write")(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/realm/heartbeat", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: playerToken})
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected a player session (no scope claim) to pass RequireScope, got status %d", rec.Code)
+	}
+}
@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"context"
+	"gateway/storage"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+type loggerContextKey struct{}
+type logFieldsContextKey struct{}
+
+// requestLogFields is attached to the request context as a pointer so that
+// AuthMiddleware, which runs inside RequestLogger, can fill in sid/user_id
+// after it resolves them — RequestLogger reads the same struct back out
+// once the handler chain returns, after next.ServeHTTP below.
+type requestLogFields struct {
+	sessionID string
+	userID    uint
+}
+
+// annotateSession records the session and user id resolved by AuthMiddleware
+// onto the requestLogFields RequestLogger put in the context, so the access
+// log line it writes after the handler returns includes who made the call.
+func annotateSession(ctx context.Context, sessionID string, userID uint) context.Context {
+	if fields, ok := ctx.Value(logFieldsContextKey{}).(*requestLogFields); ok {
+		fields.sessionID = sessionID
+		fields.userID = userID
+	}
+	return ctx
+}
+
+// LoggerFromContext returns the request-scoped logger RequestLogger attached
+// to ctx, or storage.Logger if none is present (e.g. in code called outside
+// an HTTP request, such as CleanupExpiredPendingSignups).
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerContextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return storage.Logger
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	n, err := rec.ResponseWriter.Write(b)
+	rec.bytes += n
+	return n, err
+}
+
+// RequestLogger logs one line per request — method, path, status, bytes
+// written, duration, remote IP, user agent, and (once AuthMiddleware has
+// run) session/user id — after the handler chain finishes, so the status
+// code and byte count it reports are what was actually sent.
+func RequestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		fields := &requestLogFields{}
+		ctx := context.WithValue(r.Context(), logFieldsContextKey{}, fields)
+
+		logger := storage.Logger.With("method", r.Method, "path", r.URL.Path, "remote_ip", requestIP(r), "user_agent", r.UserAgent())
+		ctx = context.WithValue(ctx, loggerContextKey{}, logger)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		args := []any{
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration_ms", time.Since(start).Milliseconds(),
+		}
+		if fields.sessionID != "" {
+			args = append(args, "sid", fields.sessionID, "user_id", fields.userID)
+		}
+		logger.Info("request", args...)
+	})
+}
+
+// requestIP takes the remote address's host part, stripping the port
+// net/http leaves on r.RemoteAddr.
+func requestIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
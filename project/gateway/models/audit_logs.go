@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// AuditLog records an admin action for operator accountability. Payload is
+// a JSON blob rather than a structured column since every action logs a
+// different shape of detail (a deleted user's id, a link request's target
+// discord id, and so on).
+type AuditLog struct {
+	ID          uint      `gorm:"primaryKey"`
+	ActorUserID uint      `gorm:"not null"`
+	Action      string    `gorm:"size:100;not null"`
+	TargetType  string    `gorm:"size:50;not null"`
+	TargetID    string    `gorm:"size:100"`
+	Payload     string    `gorm:"type:jsonb"`
+	At          time.Time `gorm:"not null"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+)
+
+// OAuthClient is a confidential client (realm/world server or trusted
+// operator tooling) registered to call the gateway's OAuth2 endpoints.
+type OAuthClient struct {
+	ID           uint   `gorm:"primaryKey"`
+	ClientID     string `gorm:"uniqueIndex;size:64;not null"`
+	ClientSecret string `gorm:"size:100;not null"` // bcrypt hash
+	Name         string `gorm:"size:100;not null"`
+	RedirectURIs string `gorm:"type:text;not null"` // newline-separated, exact match required
+	Scopes       string `gorm:"size:255;not null"`  // space-separated, e.g. "realm.read character.read"
+	CreatedAt    time.Time
+}
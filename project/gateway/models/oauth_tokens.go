@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// OAuthAuthorizationCode is a short-lived code issued to a confidential
+// client at the end of /oauth/authorize, exchanged once at /oauth/token.
+type OAuthAuthorizationCode struct {
+	ID          uint   `gorm:"primaryKey"`
+	Code        string `gorm:"uniqueIndex;size:64;not null"`
+	ClientID    string `gorm:"size:64;not null"`
+	UserID      uint   `gorm:"not null"`
+	RedirectURI string `gorm:"size:255;not null"`
+	Scope       string `gorm:"size:255;not null"`
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	ConsumedAt  *time.Time
+}
+
+// OAuthRefreshToken backs the refresh_token grant for confidential clients.
+// Distinct from the player-facing UserAuthenticatedSession refresh flow —
+// this one is scoped to a client and its granted scope.
+type OAuthRefreshToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	TokenHash string `gorm:"uniqueIndex;size:64;not null"`
+	ClientID  string `gorm:"size:64;not null"`
+	UserID    uint   `gorm:"not null"`
+	Scope     string `gorm:"size:255;not null"`
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PendingSignup holds a first-time login's provider identity while the
+// player picks a display name, so the User row is never created with a
+// borrowed third-party username as its Displayname.
+type PendingSignup struct {
+	ID              uuid.UUID `gorm:"primaryKey"`
+	Provider        string    `gorm:"size:50;not null"`
+	ProviderPayload string    `gorm:"type:text;not null"` // JSON-encoded providers.ProviderIdentity
+	CreatedAt       time.Time
+	ExpiresAt       time.Time `gorm:"not null;index"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// Realm is a registered realm server. Status starts "pending" at
+// registration and becomes "online" once heartbeats start arriving;
+// RealmListHandler additionally treats a stale LastHeartbeatAt as offline
+// regardless of Status.
+type Realm struct {
+	ID               uint   `gorm:"primaryKey"`
+	Name             string `gorm:"uniqueIndex;size:100;not null"`
+	Location         string `gorm:"size:100;not null"`
+	LocationFlag     string `gorm:"size:10;not null"`
+	Type             string `gorm:"size:20;not null"`
+	Address          string `gorm:"size:100;not null"`
+	PublicKey        string `gorm:"type:text"`
+	SharedSecretHash string `gorm:"size:100;not null" json:"-"` // bcrypt hash, never serialized
+	Population       int
+	LastHeartbeatAt  time.Time `gorm:"index"`
+	Status           string    `gorm:"size:20;not null;default:pending"` // pending, online, disabled
+	CreatedAt        time.Time
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken lets a player's client mint a new JWT for an existing
+// UserAuthenticatedSession without repeating the provider login flow.
+// Single-use: RevokedAt is set the moment the token is redeemed, whether
+// that's a normal rotation or an explicit session revocation.
+type RefreshToken struct {
+	ID        uuid.UUID `gorm:"primaryKey"`
+	SessionID uuid.UUID `gorm:"not null;index"`
+	TokenHash string    `gorm:"not null;index"`
+	UserAgent string
+	IP        string
+	CreatedAt time.Time `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+}
@@ -0,0 +1,15 @@
+package models
+
+// Role is a named permission grantable to a user, e.g. "admin". Checked by
+// middlewares.RequireRole rather than anything provider-specific, so roles
+// aren't tied to how a user logged in.
+type Role struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"uniqueIndex;size:50;not null"`
+}
+
+// UserRole attaches a Role to a user. A user can hold more than one role.
+type UserRole struct {
+	UserID uint `gorm:"primaryKey"`
+	RoleID uint `gorm:"primaryKey"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// SigningKey is a single RSA key in the gateway's rotating JWT signing key
+// set. The active key (RetiredAt is nil) is used to mint new tokens; retired
+// keys are kept around long enough for their issued tokens to expire so JWKS
+// consumers can still verify them.
+type SigningKey struct {
+	ID         uint   `gorm:"primaryKey"`
+	Kid        string `gorm:"uniqueIndex;size:64;not null"`
+	PrivatePEM string `gorm:"type:text;not null"`
+	PublicPEM  string `gorm:"type:text;not null"`
+	CreatedAt  time.Time
+	RetiredAt  *time.Time
+}
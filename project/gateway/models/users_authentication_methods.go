@@ -14,3 +14,39 @@ type AuthenticationMethodDiscord struct {
 	AvatarURL     string `gorm:"size:255"`
 	CreatedAt     time.Time
 }
+
+type AuthenticationMethodGoogle struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"uniqueIndex;not null"`
+	GoogleID  string `gorm:"uniqueIndex;size:50;not null"`
+	Username  string `gorm:"size:100;not null"`
+	Email     string `gorm:"size:100;not null"`
+	AvatarURL string `gorm:"size:255"`
+	CreatedAt time.Time
+}
+
+type AuthenticationMethodGitHub struct {
+	ID        uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"uniqueIndex;not null"`
+	GitHubID  string `gorm:"column:github_id;uniqueIndex;size:50;not null"`
+	Username  string `gorm:"size:100;not null"`
+	Email     string `gorm:"size:100;not null"`
+	AvatarURL string `gorm:"size:255"`
+	CreatedAt time.Time
+}
+
+// AuthenticationMethodEmail is the only non-redirect-based method: its
+// "identity" is a verified email address plus a bcrypt password hash rather
+// than a third-party account.
+type AuthenticationMethodEmail struct {
+	ID uint `gorm:"primaryKey"`
+	// UserID is 0 until LinkIdentity attaches this signup to a user, so
+	// unlike the other methods it's not unique: several unverified/unlinked
+	// signups would otherwise all collide on user_id=0.
+	UserID           uint   `gorm:"not null"`
+	Email            string `gorm:"uniqueIndex;size:100;not null"`
+	PasswordHash     string `gorm:"size:100;not null"`
+	VerifiedAt       *time.Time
+	VerificationCode string `gorm:"size:64"`
+	CreatedAt        time.Time
+}
@@ -11,7 +11,13 @@ type UserAuthenticatedSession struct {
 	UserID                 uint      `gorm:"not null"`
 	AuthenticationMethod   string    `gorm:"size:50;not null"`
 	AuthenticationMethodID uint      `gorm:"not null"`
+	UserAgent              string
+	IP                     string
 	LastActiveAt           time.Time `gorm:"not null"`
 	CreatedAt              time.Time `gorm:"not null"`
 	ExpiresAt              time.Time `gorm:"not null"`
+	// RevokedAt is set by DELETE /me/sessions/{id}. ParseAndVerifyJWT's
+	// caller checks it so a revoked session's outstanding JWTs and refresh
+	// tokens stop working immediately, without waiting for the JWT to expire.
+	RevokedAt *time.Time
 }
@@ -0,0 +1,21 @@
+package routes_admin
+
+import (
+	"encoding/json"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+)
+
+// ListSessionsHandler lists every non-revoked session across all users, for
+// operators investigating an incident (e.g. "is this account logged in
+// from somewhere unexpected").
+func ListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	var sessions []models.UserAuthenticatedSession
+	if err := storage.DB.Where("revoked_at IS NULL").Find(&sessions).Error; err != nil {
+		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(sessions)
+}
@@ -0,0 +1,136 @@
+// Package routes_admin holds the operator-facing subsystem gated by
+// middlewares.RequireRole("admin"): user management, account recovery, and
+// visibility into active sessions.
+package routes_admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"gateway/middlewares"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"regexp"
+
+	"gorm.io/gorm"
+)
+
+var displaynamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{2,19}$`)
+
+// ListUsersHandler lists every user for operator tooling.
+func ListUsersHandler(w http.ResponseWriter, r *http.Request) {
+	var users []models.User
+	if err := storage.DB.Find(&users).Error; err != nil {
+		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(users)
+}
+
+type createUserRequest struct {
+	Displayname string `json:"displayname"`
+}
+
+// CreateUserHandler creates a user with no linked identity yet, for cases
+// like a support ticket where an operator needs to hand a player a fresh
+// account before linking it with POST /admin/users/{id}/link-discord.
+func CreateUserHandler(w http.ResponseWriter, r *http.Request) {
+	actorUserID, _ := r.Context().Value(middlewares.UserIDKey).(uint)
+
+	var req createUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !displaynamePattern.MatchString(req.Displayname) {
+		http.Error(w, "displayname must be 3-20 characters, starting with a letter, using only letters, numbers, and underscores", http.StatusBadRequest)
+		return
+	}
+
+	user := models.User{Displayname: req.Displayname}
+	if err := storage.DB.Create(&user).Error; err != nil {
+		http.Error(w, "displayname already taken", http.StatusConflict)
+		return
+	}
+
+	_ = storage.WriteAuditLog(actorUserID, "create_user", "user", fmt.Sprintf("%d", user.ID), req)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(user)
+}
+
+// DeleteUserHandler deletes a user by id, passed as the "id" query parameter.
+func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
+	actorUserID, _ := r.Context().Value(middlewares.UserIDKey).(uint)
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing id parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := storage.DB.Delete(&models.User{}, "id = ?", id).Error; err != nil {
+		http.Error(w, "failed to delete user: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = storage.WriteAuditLog(actorUserID, "delete_user", "user", id, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type linkDiscordRequest struct {
+	DiscordID string `json:"discord_id"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+// LinkDiscordHandler attaches a Discord id to an existing user on an
+// operator's say-so — useful when a player loses access to the Discord
+// account their login used to be tied to.
+func LinkDiscordHandler(w http.ResponseWriter, r *http.Request) {
+	actorUserID, _ := r.Context().Value(middlewares.UserIDKey).(uint)
+
+	targetID := r.PathValue("id")
+	var user models.User
+	if err := storage.DB.First(&user, "id = ?", targetID).Error; err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	var req linkDiscordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.DiscordID == "" {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var existing models.AuthenticationMethodDiscord
+	err := storage.DB.Where("discord_id = ?", req.DiscordID).First(&existing).Error
+	if err == nil {
+		http.Error(w, "this discord account is already linked to a user", http.StatusConflict)
+		return
+	}
+	if err != gorm.ErrRecordNotFound {
+		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	method := models.AuthenticationMethodDiscord{
+		UserID:        user.ID,
+		DiscordID:     req.DiscordID,
+		Username:      req.Username,
+		Email:         req.Email,
+		AvatarURL:     req.AvatarURL,
+		Discriminator: "0",
+	}
+	if err := storage.DB.Create(&method).Error; err != nil {
+		http.Error(w, "failed to link discord account: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = storage.WriteAuditLog(actorUserID, "link_discord", "user", targetID, req)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -1,58 +1,108 @@
 package routes_auth
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
+	"gateway/auth/providers"
+	"gateway/middlewares"
 	"gateway/models"
 	"gateway/storage"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
-	"golang.org/x/oauth2"
 	"gorm.io/gorm"
 )
 
-func AuthCallbackHandler(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	// check state (omitted here — validate in production)
-	code := r.URL.Query().Get("code")
-	if code == "" {
-		http.Error(w, "missing code", http.StatusBadRequest)
-		return
+// CallbackHandler builds the HTTP handler for a provider's callback leg: it
+// resolves the asserted identity and either logs in the user already linked
+// to it, or — on first login — parks the identity in a PendingSignup and
+// sends the player to pick their own display name.
+func CallbackHandler(provider providers.AuthProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		identity, err := provider.Callback(r)
+		if err != nil {
+			middlewares.LoggerFromContext(r.Context()).Error("provider callback failed", "provider", provider.Name(), "err", err)
+			http.Error(w, "authentication failed", http.StatusUnauthorized)
+			return
+		}
+
+		linker, ok := provider.(providers.IdentityLinker)
+		if !ok {
+			http.Error(w, fmt.Sprintf("provider %s does not support login", provider.Name()), http.StatusBadRequest)
+			return
+		}
+
+		user, err := linker.FindLinkedUser(identity)
+		if err == nil {
+			issueSessionAndRedirect(w, r, user.ID, provider.Name(), identity.ReturnTo)
+			return
+		}
+		if err != gorm.ErrRecordNotFound {
+			middlewares.LoggerFromContext(r.Context()).Error("failed to look up linked user", "provider", provider.Name(), "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+
+		signupToken, err := beginPendingSignup(provider.Name(), identity)
+		if err != nil {
+			middlewares.LoggerFromContext(r.Context()).Error("failed to start signup", "provider", provider.Name(), "err", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		redirectURL := fmt.Sprintf("%s/signup/complete?signup_token=%s", signupRedirectBase(identity.ReturnTo), signupToken)
+		http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 	}
+}
 
-	// exchange code for token
-	token, err := storage.OAuthConfig.Exchange(ctx, code)
-	if err != nil {
-		http.Error(w, "token exchange failed: "+err.Error(), http.StatusInternalServerError)
-		return
+// defaultClientRedirect is where players land when a login began without an
+// explicit return_to, preserving the gateway's original fixed-port behavior.
+const defaultClientRedirect = "http://localhost:54320"
+
+// signupRedirectBase returns returnTo if the provider's Callback resolved
+// one from the cached OAuth state record AND it's on the configured allowlist,
+// otherwise the default client redirect. return_to is attacker-controlled
+// (it's lifted from the login request's query string before the user ever
+// authenticates), so an unrecognized value must never be used as a redirect
+// target — doing so would hand whoever set it the session JWT appended to
+// the redirect URL.
+func signupRedirectBase(returnTo string) string {
+	if returnTo == "" || !storage.IsAllowedClientRedirect(returnTo) {
+		return defaultClientRedirect
 	}
+	return returnTo
+}
 
-	// fetch user info from Discord
-	userData, err := fetchDiscordUser(ctx, token.AccessToken)
+func issueSessionAndRedirect(w http.ResponseWriter, r *http.Request, userID uint, method string, returnTo string) {
+	session, err := findOrCreateSession(r, userID, method)
 	if err != nil {
-		http.Error(w, "failed fetching user: "+err.Error(), http.StatusInternalServerError)
+		middlewares.LoggerFromContext(r.Context()).Error("failed to create session", "method", method, "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
-
-	// upsert user into DB
-	user, err := upsertUser(userData, token)
+	jwtToken, err := signSessionJWT(session)
 	if err != nil {
-		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
+		middlewares.LoggerFromContext(r.Context()).Error("failed to sign session jwt", "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
-
-	// create JWT session (short-lived)
-	jwtToken, err := createJWT(user.ID)
+	refreshToken, err := issueRefreshToken(r, session.ID)
 	if err != nil {
-		http.Error(w, "jwt error: "+err.Error(), http.StatusInternalServerError)
+		middlewares.LoggerFromContext(r.Context()).Error("failed to issue refresh token", "err", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
 
-	// set cookie (HttpOnly, Secure in prod)
+	setSessionCookies(w, jwtToken, refreshToken)
+
+	// redirect to whatever client initiated the login, falling back to the
+	// original fixed localhost port for callers that never sent return_to
+	redirectURL := fmt.Sprintf("%s?jwt=%s", signupRedirectBase(returnTo), jwtToken)
+	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
+}
+
+func setSessionCookies(w http.ResponseWriter, jwtToken, refreshToken string) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "session",
 		Value:    jwtToken,
@@ -60,133 +110,110 @@ func AuthCallbackHandler(w http.ResponseWriter, r *http.Request) {
 		HttpOnly: true,
 		Secure:   false, // <-- set to true in production with HTTPS
 		SameSite: http.SameSiteLaxMode,
-		MaxAge:   3600, // 1 hour
+		MaxAge:   int(sessionJWTTTL.Seconds()),
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false, // <-- set to true in production with HTTPS
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(sessionRefreshTokenTTL.Seconds()),
 	})
-
-	// redirect to localhost for in-game callbacks
-	redirectURL := fmt.Sprintf("http://localhost:54320?jwt=%s&code=%s", jwtToken, code)
-	http.Redirect(w, r, redirectURL, http.StatusSeeOther)
 }
 
-// ---------- Discord API fetch ----------
-type discordUserResponse struct {
-	ID            string `json:"id"`
-	Username      string `json:"username"`
-	Discriminator string `json:"discriminator"`
-	Email         string `json:"email"`
-	Avatar        string `json:"avatar"`
-	Verified      bool   `json:"verified"`
-}
+// ---------- session + JWT helpers ----------
 
-func fetchDiscordUser(ctx context.Context, accessToken string) (*discordUserResponse, error) {
-	req, _ := http.NewRequestWithContext(ctx, "GET", "https://discord.com/api/users/@me", nil)
-	req.Header.Set("Authorization", "Bearer "+accessToken)
+const (
+	sessionJWTTTL          = 1 * time.Hour
+	sessionRefreshTokenTTL = 30 * 24 * time.Hour
+)
 
-	client := http.DefaultClient
-	res, err := client.Do(req)
+// findOrCreateSession reuses the existing UserAuthenticatedSession for this
+// user+method pair, refreshing its LastActiveAt/UserAgent/IP, or creates one
+// on first login with that method.
+func findOrCreateSession(r *http.Request, userID uint, method string) (*models.UserAuthenticatedSession, error) {
+	var session models.UserAuthenticatedSession
+	err := storage.DB.Where("user_id = ? AND authentication_method = ?", userID, method).First(&session).Error
 	if err != nil {
-		return nil, err
-	}
-	defer res.Body.Close()
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("discord returned status %d", res.StatusCode)
-	}
-	var u discordUserResponse
-	if err := json.NewDecoder(res.Body).Decode(&u); err != nil {
-		return nil, err
-	}
-	return &u, nil
-}
-
-// ---------- DB upsert ----------
-func upsertUser(u *discordUserResponse, token *oauth2.Token) (*models.User, error) {
-	var discordAuth models.AuthenticationMethodDiscord
-	err := storage.DB.Where("discord_id = ?", u.ID).First(&discordAuth).Error
-	if err != nil && err == gorm.ErrRecordNotFound {
-		// Create new user and authentication method
-		user := models.User{
-			Displayname: u.Username,
-		}
-		if err := storage.DB.Create(&user).Error; err != nil {
+		if err != gorm.ErrRecordNotFound {
 			return nil, err
 		}
-
-		discordAuth = models.AuthenticationMethodDiscord{
-			UserID:        user.ID,
-			DiscordID:     u.ID,
-			Username:      u.Username,
-			Discriminator: u.Discriminator,
-			Email:         u.Email,
-			AvatarURL:     u.Avatar,
+		session = models.UserAuthenticatedSession{
+			ID:                   uuid.New(),
+			UserID:               userID,
+			AuthenticationMethod: method,
+			CreatedAt:            time.Now(),
 		}
-		if err := storage.DB.Create(&discordAuth).Error; err != nil {
-			return nil, err
-		}
-
-		return &user, nil
-	} else {
-		// Discord user exists in DB, handle updating info.
-		discordAuth.Username = u.Username
-		discordAuth.Discriminator = u.Discriminator
-		discordAuth.Email = u.Email
-		discordAuth.AvatarURL = u.Avatar
-		if err := storage.DB.Save(&discordAuth).Error; err != nil {
+		session.LastActiveAt = time.Now()
+		session.UserAgent = r.UserAgent()
+		session.IP = clientIP(r)
+		if err := storage.DB.Create(&session).Error; err != nil {
 			return nil, err
 		}
+		return &session, nil
+	}
 
-		var user models.User
-		if err := storage.DB.First(&user, discordAuth.UserID).Error; err != nil {
-			// No Linked User found? Create a new one to unstuck this user.
-			user = models.User{
-				Displayname: u.Username,
-			}
-			if err := storage.DB.Create(&user).Error; err != nil {
-				return nil, err
-			}
-			return &user, nil
-		}
-		return &user, nil
+	session.LastActiveAt = time.Now()
+	session.UserAgent = r.UserAgent()
+	session.IP = clientIP(r)
+	if err := storage.DB.Save(&session).Error; err != nil {
+		return nil, err
 	}
+	return &session, nil
 }
 
-// ---------- JWT helpers ----------
-func createJWT(userID uint) (string, error) {
-	const method = "discord"
-	const methodID = 0
-
-	// Check for existing session
-	var session models.UserAuthenticatedSession
-	err := storage.DB.Where("user_id = ? AND authentication_method = ?", userID, method).First(&session).Error
+// signSessionJWT mints a player-session JWT, signed with the current
+// rotating RS256 signing key, scoped to the given session.
+func signSessionJWT(session *models.UserAuthenticatedSession) (string, error) {
+	signingKey, err := storage.ActiveSigningKey()
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			session = models.UserAuthenticatedSession{
-				ID:                     uuid.New(),
-				UserID:                 userID,
-				AuthenticationMethod:   method,
-				AuthenticationMethodID: methodID,
-				LastActiveAt:           time.Now(),
-			}
-			if err := storage.DB.Create(&session).Error; err != nil {
-				return "", err
-			}
-		} else {
-			return "", err
-		}
+		return "", fmt.Errorf("load signing key: %w", err)
 	}
-
-	// Update last active timestamp
-	session.LastActiveAt = time.Now()
-	if err := storage.DB.Save(&session).Error; err != nil {
-		return "", err
+	privateKey, err := storage.ParseRSAPrivateKey(signingKey)
+	if err != nil {
+		return "", fmt.Errorf("parse signing key: %w", err)
 	}
 
-	// create JWT token
 	claims := jwt.MapClaims{
-		"sub": userID,
+		"iss": storage.Issuer,
+		"sub": session.UserID,
 		"sid": session.ID.String(),
-		"exp": time.Now().Add(1 * time.Hour).Unix(),
+		"exp": time.Now().Add(sessionJWTTTL).Unix(),
 		"iat": time.Now().Unix(),
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(storage.JwtSecret)
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.Kid
+	return token.SignedString(privateKey)
+}
+
+// issueRefreshToken mints a single-use refresh token for sessionID, storing
+// only its hash, and returns the raw value for the caller to hand to the
+// client.
+func issueRefreshToken(r *http.Request, sessionID uuid.UUID) (string, error) {
+	rawToken := randomToken()
+	record := &models.RefreshToken{
+		ID:        uuid.New(),
+		SessionID: sessionID,
+		TokenHash: hashToken(rawToken),
+		UserAgent: r.UserAgent(),
+		IP:        clientIP(r),
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(sessionRefreshTokenTTL),
+	}
+	if err := storage.DB.Create(record).Error; err != nil {
+		return "", err
+	}
+	return rawToken, nil
+}
+
+// clientIP takes the remote address's host part, stripping the port
+// net/http leaves on r.RemoteAddr.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
 }
@@ -0,0 +1,321 @@
+package routes_auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gateway/middlewares"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	authorizationCodeTTL = 5 * time.Minute
+	accessTokenTTL       = 1 * time.Hour
+	oauthRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// AuthorizeHandler implements the authorization_code front-channel step.
+// The caller must already hold a valid gateway session (cookie or bearer),
+// so it's wrapped in middlewares.AuthMiddleware by main.go.
+func AuthorizeHandler(w http.ResponseWriter, r *http.Request) {
+	clientID := r.URL.Query().Get("client_id")
+	redirectURI := r.URL.Query().Get("redirect_uri")
+	scope := r.URL.Query().Get("scope")
+	state := r.URL.Query().Get("state")
+
+	var client models.OAuthClient
+	if err := storage.DB.Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		http.Error(w, "unknown client_id", http.StatusBadRequest)
+		return
+	}
+	if !validRedirectURI(&client, redirectURI) {
+		http.Error(w, "redirect_uri not registered for this client", http.StatusBadRequest)
+		return
+	}
+	if !scopeSubset(scope, client.Scopes) {
+		http.Error(w, "requested scope exceeds client's registered scopes", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := authenticatedUserID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	code := &models.OAuthAuthorizationCode{
+		Code:        randomToken(),
+		ClientID:    clientID,
+		UserID:      userID,
+		RedirectURI: redirectURI,
+		Scope:       scope,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(authorizationCodeTTL),
+	}
+	if err := storage.DB.Create(code).Error; err != nil {
+		http.Error(w, "failed to issue authorization code", http.StatusInternalServerError)
+		return
+	}
+
+	dest := fmt.Sprintf("%s?code=%s&state=%s", redirectURI, code.Code, state)
+	http.Redirect(w, r, dest, http.StatusSeeOther)
+}
+
+// TokenHandler implements the authorization_code and refresh_token grants.
+func TokenHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+
+	clientID, clientSecret, ok := clientCredentials(r)
+	if !ok {
+		http.Error(w, "missing client credentials", http.StatusUnauthorized)
+		return
+	}
+	client, err := authenticateClient(clientID, clientSecret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Form.Get("grant_type") {
+	case "authorization_code":
+		issueFromAuthorizationCode(w, client, r.Form.Get("code"), r.Form.Get("redirect_uri"))
+	case "refresh_token":
+		issueFromRefreshToken(w, client, r.Form.Get("refresh_token"))
+	default:
+		http.Error(w, "unsupported grant_type", http.StatusBadRequest)
+	}
+}
+
+func issueFromAuthorizationCode(w http.ResponseWriter, client *models.OAuthClient, codeValue, redirectURI string) {
+	var code models.OAuthAuthorizationCode
+	err := storage.DB.Where("code = ? AND client_id = ?", codeValue, client.ClientID).First(&code).Error
+	if err != nil || code.ConsumedAt != nil || time.Now().After(code.ExpiresAt) || code.RedirectURI != redirectURI {
+		http.Error(w, "invalid or expired code", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	code.ConsumedAt = &now
+	if err := storage.DB.Save(&code).Error; err != nil {
+		http.Error(w, "failed to consume code", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokenResponse(w, client, code.UserID, code.Scope)
+}
+
+func issueFromRefreshToken(w http.ResponseWriter, client *models.OAuthClient, refreshToken string) {
+	var stored models.OAuthRefreshToken
+	hash := hashToken(refreshToken)
+	err := storage.DB.Where("token_hash = ? AND client_id = ?", hash, client.ClientID).First(&stored).Error
+	if err != nil || stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		http.Error(w, "invalid or expired refresh token", http.StatusBadRequest)
+		return
+	}
+
+	// single-use: revoke the presented token before minting its replacement
+	now := time.Now()
+	stored.RevokedAt = &now
+	if err := storage.DB.Save(&stored).Error; err != nil {
+		http.Error(w, "failed to rotate refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	writeTokenResponse(w, client, stored.UserID, stored.Scope)
+}
+
+func writeTokenResponse(w http.ResponseWriter, client *models.OAuthClient, userID uint, scope string) {
+	accessToken, err := createScopedJWT(userID, client.ClientID, scope)
+	if err != nil {
+		http.Error(w, "failed to mint access token", http.StatusInternalServerError)
+		return
+	}
+
+	refreshToken := randomToken()
+	record := &models.OAuthRefreshToken{
+		TokenHash: hashToken(refreshToken),
+		ClientID:  client.ClientID,
+		UserID:    userID,
+		Scope:     scope,
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(oauthRefreshTokenTTL),
+	}
+	if err := storage.DB.Create(record).Error; err != nil {
+		http.Error(w, "failed to issue refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(accessTokenTTL.Seconds()),
+		"refresh_token": refreshToken,
+		"scope":         scope,
+	})
+}
+
+// UserinfoHandler returns claims about the user identified by the bearer
+// token's subject (sub and displayname) together with the token's "scope"
+// claim. The fields returned are the same whatever that scope is.
+func UserinfoHandler(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := middlewares.ParseAndVerifyJWT(strings.TrimPrefix(auth, "Bearer "))
+	if err != nil {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	sub, _ := claims["sub"].(float64)
+	var user models.User
+	if err := storage.DB.First(&user, uint(sub)).Error; err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"sub":         fmt.Sprintf("%d", user.ID),
+		"displayname": user.Displayname,
+		"scope":       claims["scope"],
+	})
+}
+
+// RevokeHandler revokes a refresh token per RFC 7009 — best-effort, always
+// returns 200 whether or not the token existed so clients can't probe validity.
+func RevokeHandler(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid form body", http.StatusBadRequest)
+		return
+	}
+	clientID, clientSecret, ok := clientCredentials(r)
+	if !ok {
+		http.Error(w, "missing client credentials", http.StatusUnauthorized)
+		return
+	}
+	client, err := authenticateClient(clientID, clientSecret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	storage.DB.Model(&models.OAuthRefreshToken{}).
+		Where("token_hash = ? AND client_id = ?", hashToken(r.Form.Get("token")), client.ClientID).
+		Update("revoked_at", &now)
+	w.WriteHeader(http.StatusOK)
+}
+
+// ---------- helpers ----------
+
+func createScopedJWT(userID uint, clientID, scope string) (string, error) {
+	signingKey, err := storage.ActiveSigningKey()
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := storage.ParseRSAPrivateKey(signingKey)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   storage.Issuer,
+		"aud":   clientID,
+		"sub":   userID,
+		"scope": scope,
+		"exp":   time.Now().Add(accessTokenTTL).Unix(),
+		"iat":   time.Now().Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.Kid
+	return token.SignedString(privateKey)
+}
+
+func authenticatedUserID(r *http.Request) (uint, error) {
+	sessionID := r.Context().Value(middlewares.SessionIDKey)
+	if sessionID == nil {
+		return 0, fmt.Errorf("no session")
+	}
+	var session models.UserAuthenticatedSession
+	if err := storage.DB.First(&session, "id = ?", sessionID).Error; err != nil {
+		return 0, fmt.Errorf("session not found")
+	}
+	return session.UserID, nil
+}
+
+func clientCredentials(r *http.Request) (string, string, bool) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		return id, secret, true
+	}
+	id := r.Form.Get("client_id")
+	secret := r.Form.Get("client_secret")
+	if id == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+func authenticateClient(clientID, clientSecret string) (*models.OAuthClient, error) {
+	var client models.OAuthClient
+	if err := storage.DB.Where("client_id = ?", clientID).First(&client).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("unknown client")
+		}
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(client.ClientSecret), []byte(clientSecret)) != nil {
+		return nil, fmt.Errorf("invalid client credentials")
+	}
+	return &client, nil
+}
+
+func validRedirectURI(client *models.OAuthClient, redirectURI string) bool {
+	for _, uri := range strings.Split(client.RedirectURIs, "\n") {
+		if strings.TrimSpace(uri) == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+func scopeSubset(requested, registered string) bool {
+	registeredSet := make(map[string]bool)
+	for _, s := range strings.Fields(registered) {
+		registeredSet[s] = true
+	}
+	for _, s := range strings.Fields(requested) {
+		if !registeredSet[s] {
+			return false
+		}
+	}
+	return true
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
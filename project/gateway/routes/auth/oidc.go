@@ -0,0 +1,68 @@
+package routes_auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"gateway/storage"
+	"math/big"
+	"net/http"
+)
+
+// DiscoveryHandler serves the OIDC discovery document at
+// /.well-known/openid-configuration so realm/world servers can locate the
+// gateway's JWKS and OAuth2 endpoints without hard-coding them.
+func DiscoveryHandler(w http.ResponseWriter, r *http.Request) {
+	doc := map[string]interface{}{
+		"issuer":                                storage.Issuer,
+		"authorization_endpoint":                storage.Issuer + "/oauth/authorize",
+		"token_endpoint":                        storage.Issuer + "/oauth/token",
+		"userinfo_endpoint":                     storage.Issuer + "/oauth/userinfo",
+		"revocation_endpoint":                   storage.Issuer + "/oauth/revoke",
+		"jwks_uri":                              storage.Issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{"realm.read", "character.read"},
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(doc)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKSHandler serves the gateway's public signing keys as a JWK Set so
+// RS256-signed tokens can be verified without sharing storage.JwtSecret.
+func JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	keys, err := storage.AllSigningKeys()
+	if err != nil {
+		http.Error(w, "failed to load signing keys", http.StatusInternalServerError)
+		return
+	}
+
+	jwks := make([]jwk, 0, len(keys))
+	for _, key := range keys {
+		pub, err := storage.ParseRSAPublicKey(&key)
+		if err != nil {
+			continue
+		}
+		jwks = append(jwks, jwk{
+			Kty: "RSA",
+			Use: "sig",
+			Kid: key.Kid,
+			Alg: "RS256",
+			N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": jwks})
+}
@@ -0,0 +1,66 @@
+package routes_auth
+
+import (
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"time"
+)
+
+// RefreshHandler rotates the refresh_token cookie and mints a fresh session
+// JWT, so a player's client can stay logged in past the JWT's short expiry
+// without repeating the provider login flow. The presented refresh token is
+// single-use: it's revoked here whether or not the rotation succeeds.
+func RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie("refresh_token")
+	if err != nil {
+		http.Error(w, "missing refresh_token cookie", http.StatusUnauthorized)
+		return
+	}
+
+	var stored models.RefreshToken
+	err = storage.DB.Where("token_hash = ?", hashToken(cookie.Value)).First(&stored).Error
+	if err != nil || stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		http.Error(w, "invalid or expired refresh token", http.StatusUnauthorized)
+		return
+	}
+
+	now := time.Now()
+	stored.RevokedAt = &now
+	if err := storage.DB.Save(&stored).Error; err != nil {
+		http.Error(w, "failed to rotate refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	var session models.UserAuthenticatedSession
+	if err := storage.DB.First(&session, "id = ?", stored.SessionID).Error; err != nil {
+		http.Error(w, "session not found", http.StatusUnauthorized)
+		return
+	}
+	if session.RevokedAt != nil {
+		http.Error(w, "session revoked", http.StatusUnauthorized)
+		return
+	}
+
+	session.LastActiveAt = time.Now()
+	session.UserAgent = r.UserAgent()
+	session.IP = clientIP(r)
+	if err := storage.DB.Save(&session).Error; err != nil {
+		http.Error(w, "failed to refresh session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	jwtToken, err := signSessionJWT(&session)
+	if err != nil {
+		http.Error(w, "jwt error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	refreshToken, err := issueRefreshToken(r, session.ID)
+	if err != nil {
+		http.Error(w, "refresh token error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	setSessionCookies(w, jwtToken, refreshToken)
+	w.WriteHeader(http.StatusNoContent)
+}
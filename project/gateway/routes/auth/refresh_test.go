@@ -0,0 +1,96 @@
+package routes_auth
+
+import (
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func setupRefreshTestDB(t *testing.T) *models.UserAuthenticatedSession {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := db.AutoMigrate(
+		&models.SigningKey{},
+		&models.UserAuthenticatedSession{},
+		&models.RefreshToken{},
+	); err != nil {
+		t.Fatalf("automigrate: %v", err)
+	}
+	storage.DB = db
+
+	session := &models.UserAuthenticatedSession{
+		ID:           uuid.New(),
+		UserID:       1,
+		LastActiveAt: time.Now(),
+		CreatedAt:    time.Now(),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	}
+	if err := storage.DB.Create(session).Error; err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return session
+}
+
+// TestRefreshHandlerSingleUse verifies that redeeming a refresh token rotates
+// it (mints a fresh pair) and revokes the presented token, so replaying the
+// same cookie a second time is rejected.
+func TestRefreshHandlerSingleUse(t *testing.T) {
+	session := setupRefreshTestDB(t)
+
+	rawToken, err := issueRefreshToken(httptest.NewRequest(http.MethodPost, "/auth/refresh", nil), session.ID)
+	if err != nil {
+		t.Fatalf("issue refresh token: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: rawToken})
+	rec := httptest.NewRecorder()
+	RefreshHandler(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected first use to succeed with 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	replay := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+	replay.AddCookie(&http.Cookie{Name: "refresh_token", Value: rawToken})
+	replayRec := httptest.NewRecorder()
+	RefreshHandler(replayRec, replay)
+
+	if replayRec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected replaying a consumed refresh token to be rejected, got %d", replayRec.Code)
+	}
+}
+
+func TestRefreshHandlerRejectsRevokedSession(t *testing.T) {
+	session := setupRefreshTestDB(t)
+
+	now := time.Now()
+	session.RevokedAt = &now
+	if err := storage.DB.Save(session).Error; err != nil {
+		t.Fatalf("revoke session: %v", err)
+	}
+
+	rawToken, err := issueRefreshToken(httptest.NewRequest(http.MethodPost, "/auth/refresh", nil), session.ID)
+	if err != nil {
+		t.Fatalf("issue refresh token: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "refresh_token", Value: rawToken})
+	rec := httptest.NewRecorder()
+	RefreshHandler(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a revoked session to reject refresh, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,177 @@
+package routes_auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gateway/auth/providers"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const pendingSignupTTL = 15 * time.Minute
+
+var displaynamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{2,19}$`)
+
+// beginPendingSignup stores a first-login identity and returns a short-lived
+// "signup" JWT (a "psid" claim, distinct from the "sid" session claim) that
+// authorizes exactly one call to /signup/complete for this pending row.
+func beginPendingSignup(provider string, identity *providers.ProviderIdentity) (string, error) {
+	payload, err := json.Marshal(identity)
+	if err != nil {
+		return "", err
+	}
+
+	pending := models.PendingSignup{
+		ID:              uuid.New(),
+		Provider:        provider,
+		ProviderPayload: string(payload),
+		CreatedAt:       time.Now(),
+		ExpiresAt:       time.Now().Add(pendingSignupTTL),
+	}
+	if err := storage.DB.Create(&pending).Error; err != nil {
+		return "", err
+	}
+
+	signingKey, err := storage.ActiveSigningKey()
+	if err != nil {
+		return "", err
+	}
+	privateKey, err := storage.ParseRSAPrivateKey(signingKey)
+	if err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"iss":  storage.Issuer,
+		"psid": pending.ID.String(),
+		"exp":  pending.ExpiresAt.Unix(),
+		"iat":  time.Now().Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = signingKey.Kid
+	return token.SignedString(privateKey)
+}
+
+type signupCompleteRequest struct {
+	SignupToken string `json:"signup_token"`
+	Displayname string `json:"displayname"`
+}
+
+// SignupCompleteHandler consumes a pending signup and its caller-chosen
+// display name, creating the User and the provider's link row together —
+// only now, rather than at first callback.
+func SignupCompleteHandler(w http.ResponseWriter, r *http.Request) {
+	var req signupCompleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !displaynamePattern.MatchString(req.Displayname) {
+		http.Error(w, "displayname must be 3-20 characters, starting with a letter, using only letters, numbers, and underscores", http.StatusBadRequest)
+		return
+	}
+	var existing models.User
+	if err := storage.DB.Where("displayname = ?", req.Displayname).First(&existing).Error; err == nil {
+		http.Error(w, "that displayname is already taken", http.StatusConflict)
+		return
+	}
+
+	claims, err := verifySignupToken(req.SignupToken)
+	if err != nil {
+		http.Error(w, "invalid or expired signup token: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+	psid, _ := claims["psid"].(string)
+
+	var pending models.PendingSignup
+	if err := storage.DB.Where("id = ? AND expires_at > ?", psid, time.Now()).First(&pending).Error; err != nil {
+		http.Error(w, "pending signup not found or expired", http.StatusGone)
+		return
+	}
+
+	provider, ok := providers.Get(pending.Provider)
+	if !ok {
+		http.Error(w, "provider no longer registered", http.StatusInternalServerError)
+		return
+	}
+	linker, ok := provider.(providers.IdentityLinker)
+	if !ok {
+		http.Error(w, "provider does not support linking", http.StatusInternalServerError)
+		return
+	}
+
+	var identity providers.ProviderIdentity
+	if err := json.Unmarshal([]byte(pending.ProviderPayload), &identity); err != nil {
+		http.Error(w, "corrupt pending signup", http.StatusInternalServerError)
+		return
+	}
+
+	user := models.User{Displayname: req.Displayname}
+	if err := storage.DB.Create(&user).Error; err != nil {
+		http.Error(w, "displayname already taken", http.StatusConflict)
+		return
+	}
+	if err := linker.LinkIdentity(user.ID, &identity); err != nil {
+		http.Error(w, "failed to link identity: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := storage.DB.Delete(&pending).Error; err != nil {
+		storage.Logger.Error("failed to delete consumed pending signup", "pending_signup_id", pending.ID, "err", err)
+	}
+
+	issueSessionAndRedirect(w, r, user.ID, pending.Provider, identity.ReturnTo)
+}
+
+func verifySignupToken(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		kid, ok := t.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("missing kid header")
+		}
+		signingKey, err := storage.SigningKeyByKid(kid)
+		if err != nil {
+			return nil, err
+		}
+		return storage.ParseRSAPublicKey(signingKey)
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("invalid claims")
+	}
+	if _, ok := claims["psid"]; !ok {
+		return nil, fmt.Errorf("not a signup token")
+	}
+	return claims, nil
+}
+
+// CleanupExpiredPendingSignups periodically drops pending signups whose TTL
+// elapsed before the player finished choosing a display name. It runs until
+// ctx is cancelled.
+func CleanupExpiredPendingSignups(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			res := storage.DB.Where("expires_at < ?", time.Now()).Delete(&models.PendingSignup{})
+			if res.Error != nil {
+				storage.Logger.Error("pending signup cleanup failed", "err", res.Error)
+			} else if res.RowsAffected > 0 {
+				storage.Logger.Info("cleaned up expired pending signups", "count", res.RowsAffected)
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package routes_client
+
+import (
+	"fmt"
+	"gateway/auth/providers"
+	"gateway/middlewares"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+)
+
+// LinkHandler attaches a second identity to the already-logged-in user. The
+// provider does its normal Callback resolution (reading whatever the
+// provider-specific payload looks like off the request) but the result is
+// linked to the current user instead of starting a new session.
+func LinkHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := providers.Get(r.PathValue("provider"))
+	if !ok {
+		http.Error(w, "unknown provider", http.StatusNotFound)
+		return
+	}
+	linker, ok := provider.(providers.IdentityLinker)
+	if !ok {
+		http.Error(w, "provider does not support linking", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := currentUserID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	identity, err := provider.Callback(r)
+	if err != nil {
+		http.Error(w, "failed to verify identity: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := linker.LinkIdentity(userID, identity); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnlinkHandler detaches a provider identity from the current user.
+func UnlinkHandler(w http.ResponseWriter, r *http.Request) {
+	provider, ok := providers.Get(r.PathValue("provider"))
+	if !ok {
+		http.Error(w, "unknown provider", http.StatusNotFound)
+		return
+	}
+
+	userID, err := currentUserID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err := provider.Unlink(userID); err != nil {
+		http.Error(w, "failed to unlink: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func currentUserID(r *http.Request) (uint, error) {
+	sessionIDVal := r.Context().Value(middlewares.SessionIDKey)
+	if sessionIDVal == nil {
+		return 0, fmt.Errorf("no active session")
+	}
+	var session models.UserAuthenticatedSession
+	if err := storage.DB.First(&session, "id = ?", sessionIDVal).Error; err != nil {
+		return 0, fmt.Errorf("no active session")
+	}
+	return session.UserID, nil
+}
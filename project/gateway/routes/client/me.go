@@ -3,6 +3,7 @@ package routes_client
 import (
 	"encoding/json"
 	"fmt"
+	"gateway/auth/providers"
 	"gateway/middlewares"
 	"gateway/models"
 	"gateway/storage"
@@ -28,18 +29,14 @@ func MeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	authenticationMethods := []string{
-		"discord",
-	}
-
-	var linkedMethods []map[string]string
+	var linkedMethods []map[string]interface{}
 
-	for _, method := range authenticationMethods {
-		switch method {
+	for name := range providers.All() {
+		switch name {
 		case "discord":
 			var dbMethod models.AuthenticationMethodDiscord
 			if err := storage.DB.Where("user_id = ?", user.ID).First(&dbMethod).Error; err == nil {
-				linkedMethods = append(linkedMethods, map[string]string{
+				linkedMethods = append(linkedMethods, map[string]interface{}{
 					"method":          "discord",
 					"discord_id":      dbMethod.DiscordID,
 					"username":        dbMethod.Username,
@@ -49,6 +46,36 @@ func MeHandler(w http.ResponseWriter, r *http.Request) {
 					"avatar_url_png":  fmt.Sprintf("https://cdn.discordapp.com/avatars/%s/%s.png", dbMethod.DiscordID, dbMethod.AvatarURL),
 				})
 			}
+		case "google":
+			var dbMethod models.AuthenticationMethodGoogle
+			if err := storage.DB.Where("user_id = ?", user.ID).First(&dbMethod).Error; err == nil {
+				linkedMethods = append(linkedMethods, map[string]interface{}{
+					"method":     "google",
+					"google_id":  dbMethod.GoogleID,
+					"username":   dbMethod.Username,
+					"email":      dbMethod.Email,
+					"avatar_url": dbMethod.AvatarURL,
+				})
+			}
+		case "github":
+			var dbMethod models.AuthenticationMethodGitHub
+			if err := storage.DB.Where("user_id = ?", user.ID).First(&dbMethod).Error; err == nil {
+				linkedMethods = append(linkedMethods, map[string]interface{}{
+					"method":     "github",
+					"github_id":  dbMethod.GitHubID,
+					"username":   dbMethod.Username,
+					"email":      dbMethod.Email,
+					"avatar_url": dbMethod.AvatarURL,
+				})
+			}
+		case "email":
+			var dbMethod models.AuthenticationMethodEmail
+			if err := storage.DB.Where("user_id = ?", user.ID).First(&dbMethod).Error; err == nil {
+				linkedMethods = append(linkedMethods, map[string]interface{}{
+					"method": "email",
+					"email":  dbMethod.Email,
+				})
+			}
 		}
 	}
 	// return some JSON about the user
@@ -2,29 +2,35 @@ package routes_client
 
 import (
 	"encoding/json"
+	"gateway/models"
+	"gateway/storage"
 	"net/http"
+	"time"
 )
 
 func RealmListHandler(w http.ResponseWriter, r *http.Request) {
-	// For demonstration, return a static realmlist
-	realmlist := []map[string]interface{}{
-		{
-			"name":          "Example Realm 1",
-			"location":      "North America / Los Angeles",
-			"location-flag": "US",
-			"type":          "PvP",
-			"population":    "High",
-			"address":       "127.0.0.1:4242",
-		},
-		{
-			"name":          "Example Realm 2",
-			"location":      "United Kingdom / London",
-			"location-flag": "UK",
-			"type":          "PvE",
-			"population":    "Medium",
-			"address":       "1.1.1.1:4242",
-		},
+	var realms []models.Realm
+	if err := storage.DB.Where("status != ?", "disabled").Find(&realms).Error; err != nil {
+		http.Error(w, "failed to load realms", http.StatusInternalServerError)
+		return
 	}
+
+	staleCutoff := time.Now().Add(-storage.RealmStalenessWindow)
+	realmlist := make([]map[string]interface{}, 0, len(realms))
+	for _, realm := range realms {
+		if realm.LastHeartbeatAt.Before(staleCutoff) {
+			continue
+		}
+		realmlist = append(realmlist, map[string]interface{}{
+			"name":          realm.Name,
+			"location":      realm.Location,
+			"location-flag": realm.LocationFlag,
+			"type":          realm.Type,
+			"population":    realm.Population,
+			"address":       realm.Address,
+		})
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(realmlist)
 }
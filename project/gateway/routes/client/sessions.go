@@ -0,0 +1,105 @@
+package routes_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"gateway/middlewares"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type sessionResponse struct {
+	ID                   string    `json:"id"`
+	AuthenticationMethod string    `json:"authentication_method"`
+	UserAgent            string    `json:"user_agent"`
+	IP                   string    `json:"ip"`
+	LastActiveAt         time.Time `json:"last_active_at"`
+	CreatedAt            time.Time `json:"created_at"`
+	Current              bool      `json:"current"`
+}
+
+// ListSessionsHandler lists every non-revoked session belonging to the
+// current user, so a player can spot an unfamiliar device and revoke it.
+func ListSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	current, err := currentSession(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var sessions []models.UserAuthenticatedSession
+	if err := storage.DB.Where("user_id = ? AND revoked_at IS NULL", current.UserID).Find(&sessions).Error; err != nil {
+		http.Error(w, "db error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	out := make([]sessionResponse, 0, len(sessions))
+	for _, s := range sessions {
+		out = append(out, sessionResponse{
+			ID:                   s.ID.String(),
+			AuthenticationMethod: s.AuthenticationMethod,
+			UserAgent:            s.UserAgent,
+			IP:                   s.IP,
+			LastActiveAt:         s.LastActiveAt,
+			CreatedAt:            s.CreatedAt,
+			Current:              s.ID == current.ID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(out)
+}
+
+// RevokeSessionHandler revokes one of the current user's own sessions. This
+// also invalidates every outstanding refresh token for that session, since
+// POST /auth/refresh and AuthMiddleware both check the session's RevokedAt.
+func RevokeSessionHandler(w http.ResponseWriter, r *http.Request) {
+	current, err := currentSession(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	targetID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid session id", http.StatusBadRequest)
+		return
+	}
+
+	var target models.UserAuthenticatedSession
+	if err := storage.DB.Where("id = ? AND user_id = ?", targetID, current.UserID).First(&target).Error; err != nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	now := time.Now()
+	target.RevokedAt = &now
+	if err := storage.DB.Save(&target).Error; err != nil {
+		http.Error(w, "failed to revoke session: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	storage.DB.Model(&models.RefreshToken{}).
+		Where("session_id = ? AND revoked_at IS NULL", target.ID).
+		Update("revoked_at", &now)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentSession resolves the UserAuthenticatedSession the request's JWT was
+// issued for, the same way currentUserID does but keeping the session row
+// so callers can compare it against the ones being listed.
+func currentSession(r *http.Request) (*models.UserAuthenticatedSession, error) {
+	sessionIDVal := r.Context().Value(middlewares.SessionIDKey)
+	if sessionIDVal == nil {
+		return nil, fmt.Errorf("no active session")
+	}
+	var session models.UserAuthenticatedSession
+	if err := storage.DB.First(&session, "id = ?", sessionIDVal).Error; err != nil {
+		return nil, fmt.Errorf("no active session")
+	}
+	return &session, nil
+}
@@ -0,0 +1,10 @@
+// Package routes_index holds the gateway's root route.
+package routes_index
+
+import "net/http"
+
+// IndexHandler serves as a liveness check — something for a load balancer
+// or a developer's browser to hit that isn't under any provider or auth path.
+func IndexHandler(w http.ResponseWriter, r *http.Request) {
+	w.Write([]byte("Godot-MMORPG gateway is running."))
+}
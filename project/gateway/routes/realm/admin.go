@@ -0,0 +1,37 @@
+package routes_realm
+
+import (
+	"encoding/json"
+	"gateway/middlewares"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+)
+
+// AdminListHandler returns every registered realm regardless of heartbeat
+// staleness, for operator tooling.
+func AdminListHandler(w http.ResponseWriter, r *http.Request) {
+	var realms []models.Realm
+	if err := storage.DB.Find(&realms).Error; err != nil {
+		http.Error(w, "failed to load realms", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(realms)
+}
+
+// AdminDisableHandler marks a realm disabled so RealmListHandler stops
+// surfacing it, without deregistering it entirely.
+func AdminDisableHandler(w http.ResponseWriter, r *http.Request) {
+	actorUserID, _ := r.Context().Value(middlewares.UserIDKey).(uint)
+
+	id := r.PathValue("id")
+	if err := storage.DB.Model(&models.Realm{}).Where("id = ?", id).Update("status", "disabled").Error; err != nil {
+		http.Error(w, "failed to disable realm", http.StatusInternalServerError)
+		return
+	}
+
+	_ = storage.WriteAuditLog(actorUserID, "disable_realm", "realm", id, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,8 @@
+package routes_realm
+
+import "errors"
+
+var (
+	errMissingCredentials = errors.New("missing or malformed Authorization header")
+	errInvalidCredentials = errors.New("invalid realm credentials")
+)
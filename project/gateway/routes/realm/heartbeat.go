@@ -0,0 +1,84 @@
+package routes_realm
+
+import (
+	"encoding/json"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type heartbeatRequest struct {
+	Population int `json:"population"`
+}
+
+// HeartbeatHandler authenticates the realm with its shared secret (sent as
+// "Realm <id>:<secret>", checked against the stored bcrypt hash) and
+// records its liveness and current population.
+func HeartbeatHandler(w http.ResponseWriter, r *http.Request) {
+	realm, err := authenticateRealm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var req heartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	realm.LastHeartbeatAt = time.Now()
+	realm.Population = req.Population
+	if realm.Status == "pending" {
+		realm.Status = "online"
+	}
+	if err := storage.DB.Save(realm).Error; err != nil {
+		http.Error(w, "failed to record heartbeat", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeregisterHandler removes a realm from the registry entirely.
+func DeregisterHandler(w http.ResponseWriter, r *http.Request) {
+	realm, err := authenticateRealm(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err := storage.DB.Delete(realm).Error; err != nil {
+		http.Error(w, "failed to deregister realm", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func authenticateRealm(r *http.Request) (*models.Realm, error) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Realm ") {
+		return nil, errMissingCredentials
+	}
+	idAndSecret := strings.SplitN(strings.TrimPrefix(auth, "Realm "), ":", 2)
+	if len(idAndSecret) != 2 {
+		return nil, errMissingCredentials
+	}
+	realmID, err := strconv.ParseUint(idAndSecret[0], 10, 64)
+	if err != nil {
+		return nil, errMissingCredentials
+	}
+
+	var realm models.Realm
+	if err := storage.DB.First(&realm, uint(realmID)).Error; err != nil {
+		return nil, errInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(realm.SharedSecretHash), []byte(idAndSecret[1])) != nil {
+		return nil, errInvalidCredentials
+	}
+	return &realm, nil
+}
@@ -0,0 +1,84 @@
+// Package routes_realm holds the realm-facing subsystem: registration,
+// heartbeats, and deregistration for realm servers backing RealmListHandler.
+package routes_realm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"gateway/middlewares"
+	"gateway/models"
+	"gateway/storage"
+	"net/http"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type registerRequest struct {
+	Name         string `json:"name"`
+	Location     string `json:"location"`
+	LocationFlag string `json:"location_flag"`
+	Type         string `json:"type"`
+	Address      string `json:"address"`
+	PublicKey    string `json:"public_key"`
+}
+
+// RegisterHandler is a one-time, admin-authenticated registration: it
+// creates the Realm row and returns the shared secret exactly once (only
+// its bcrypt hash is persisted).
+func RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	actorUserID, _ := r.Context().Value(middlewares.UserIDKey).(uint)
+
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == "" || req.Address == "" {
+		http.Error(w, "name and address are required", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := generateSharedSecret()
+	if err != nil {
+		http.Error(w, "failed to generate shared secret", http.StatusInternalServerError)
+		return
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "failed to hash shared secret", http.StatusInternalServerError)
+		return
+	}
+
+	realm := models.Realm{
+		Name:             req.Name,
+		Location:         req.Location,
+		LocationFlag:     req.LocationFlag,
+		Type:             req.Type,
+		Address:          req.Address,
+		PublicKey:        req.PublicKey,
+		SharedSecretHash: string(hash),
+		Status:           "pending",
+	}
+	if err := storage.DB.Create(&realm).Error; err != nil {
+		http.Error(w, "realm already registered or db error: "+err.Error(), http.StatusConflict)
+		return
+	}
+
+	_ = storage.WriteAuditLog(actorUserID, "register_realm", "realm", fmt.Sprintf("%d", realm.ID), req)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"realm_id":      realm.ID,
+		"shared_secret": secret, // returned once; the DB only ever stores its hash
+	})
+}
+
+func generateSharedSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
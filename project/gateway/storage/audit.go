@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"encoding/json"
+	"gateway/models"
+	"time"
+)
+
+// WriteAuditLog records an admin action so operator tooling is itself
+// auditable. payload is marshaled to JSON; pass anything JSON-serializable
+// describing what changed.
+func WriteAuditLog(actorUserID uint, action, targetType, targetID string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return DB.Create(&models.AuditLog{
+		ActorUserID: actorUserID,
+		Action:      action,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Payload:     string(data),
+		At:          time.Now(),
+	}).Error
+}
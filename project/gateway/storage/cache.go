@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"os"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var Cache *redis.Client
+
+// InitCache connects to the Redis instance backing OAuth state and other
+// short-lived records. It fails fast, matching InitDB's behavior.
+func InitCache(dsn string) {
+	opts, err := redis.ParseURL(dsn)
+	if err != nil {
+		Logger.Error("invalid CACHE_DSN", "err", err)
+		os.Exit(1)
+	}
+
+	Cache = redis.NewClient(opts)
+	if err := Cache.Ping(context.Background()).Err(); err != nil {
+		Logger.Error("failed to connect to cache", "err", err)
+		os.Exit(1)
+	}
+}
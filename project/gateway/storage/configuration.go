@@ -1,8 +1,10 @@
 package storage
 
 import (
-	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/endpoints"
@@ -10,34 +12,76 @@ import (
 
 // ---------- Config (from env) ----------
 var (
-	JwtSecret   []byte
-	OAuthConfig *oauth2.Config
-	DSN         string
-	CacheDSN    string
+	JwtSecret               []byte
+	OAuthConfig             *oauth2.Config // Discord, kept as OAuthConfig for backwards compatibility
+	GoogleOAuthConfig       *oauth2.Config // nil unless GOOGLE_CLIENT_ID is set
+	GitHubOAuthConfig       *oauth2.Config // nil unless GITHUB_CLIENT_ID is set
+	DSN                     string
+	CacheDSN                string
+	Issuer                  string
+	RealmStalenessWindow    time.Duration
+	AdminDiscordIDs         []string // bootstrapped into the "admin" role by SeedAdminRoles
+	ClientRedirectAllowlist []string // exact-match allowlist for login return_to, see IsAllowedClientRedirect
 )
 
 func InitializeConfiguration() {
 
 	// read config from env
 	var (
-		clientID        = os.Getenv("DISCORD_CLIENT_ID")
-		clientSecret    = os.Getenv("DISCORD_CLIENT_SECRET")
-		redirectURL     = os.Getenv("DISCORD_REDIRECT_URL") // e.g. https://yourdomain.com/auth/discord/callback
-		jwtSecretString = os.Getenv("JWT_SECRET")           // must be set
-		dsn             = os.Getenv("DATABASE_DSN")         // e.g. a Postgres DSN
-		cacheDSN        = os.Getenv("CACHE_DSN")            // e.g. a Redis DSN
+		clientID           = os.Getenv("DISCORD_CLIENT_ID")
+		clientSecret       = os.Getenv("DISCORD_CLIENT_SECRET")
+		redirectURL        = os.Getenv("DISCORD_REDIRECT_URL") // e.g. https://yourdomain.com/auth/discord/callback
+		jwtSecretString    = os.Getenv("JWT_SECRET")           // must be set
+		dsn                = os.Getenv("DATABASE_DSN")         // e.g. a Postgres DSN
+		cacheDSN           = os.Getenv("CACHE_DSN")            // e.g. a Redis DSN
+		issuer             = os.Getenv("OIDC_ISSUER")          // e.g. https://auth.yourdomain.com
+		googleClientID     = os.Getenv("GOOGLE_CLIENT_ID")
+		googleClientSecret = os.Getenv("GOOGLE_CLIENT_SECRET")
+		googleRedirectURL  = os.Getenv("GOOGLE_REDIRECT_URL")
+		githubClientID     = os.Getenv("GITHUB_CLIENT_ID")
+		githubClientSecret = os.Getenv("GITHUB_CLIENT_SECRET")
+		githubRedirectURL  = os.Getenv("GITHUB_REDIRECT_URL")
+		stalenessSeconds   = os.Getenv("REALM_HEARTBEAT_STALENESS_SECONDS")
+		adminDiscordIDs    = os.Getenv("ADMIN_DISCORD_IDS")        // comma-separated, bootstraps the first operator(s)
+		clientRedirects    = os.Getenv("ALLOWED_CLIENT_REDIRECTS") // comma-separated, see ClientRedirectAllowlist
 	)
 
 	// basic env check
 	if clientID == "" || clientSecret == "" || redirectURL == "" || jwtSecretString == "" {
-		log.Fatal("DISCORD_CLIENT_ID, DISCORD_CLIENT_SECRET, DISCORD_REDIRECT_URL, and JWT_SECRET must be set")
+		Logger.Error("missing required configuration", "need", "DISCORD_CLIENT_ID, DISCORD_CLIENT_SECRET, DISCORD_REDIRECT_URL, JWT_SECRET")
+		os.Exit(1)
+	}
+	if issuer == "" {
+		issuer = "http://localhost:8080"
 	}
 
 	DSN = dsn
 	CacheDSN = cacheDSN
+	Issuer = issuer
+
+	RealmStalenessWindow = 30 * time.Second
+	if stalenessSeconds != "" {
+		if n, err := strconv.Atoi(stalenessSeconds); err == nil && n > 0 {
+			RealmStalenessWindow = time.Duration(n) * time.Second
+		}
+	}
 
 	JwtSecret = []byte(jwtSecretString)
 
+	AdminDiscordIDs = nil
+	for _, id := range strings.Split(adminDiscordIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			AdminDiscordIDs = append(AdminDiscordIDs, id)
+		}
+	}
+
+	ClientRedirectAllowlist = nil
+	for _, redirect := range strings.Split(clientRedirects, ",") {
+		if redirect = strings.TrimSpace(redirect); redirect != "" {
+			ClientRedirectAllowlist = append(ClientRedirectAllowlist, redirect)
+		}
+	}
+
 	// OAuth2 config for Discord
 	OAuthConfig = &oauth2.Config{
 		ClientID:     clientID,
@@ -46,4 +90,39 @@ func InitializeConfiguration() {
 		RedirectURL:  redirectURL,
 		Scopes:       []string{"identify", "email"},
 	}
+
+	// Google and GitHub are optional secondary providers — only wired up if
+	// their credentials are present in the environment.
+	if googleClientID != "" && googleClientSecret != "" {
+		GoogleOAuthConfig = &oauth2.Config{
+			ClientID:     googleClientID,
+			ClientSecret: googleClientSecret,
+			Endpoint:     endpoints.Google,
+			RedirectURL:  googleRedirectURL,
+			Scopes:       []string{"openid", "email", "profile"},
+		}
+	}
+	if githubClientID != "" && githubClientSecret != "" {
+		GitHubOAuthConfig = &oauth2.Config{
+			ClientID:     githubClientID,
+			ClientSecret: githubClientSecret,
+			Endpoint:     endpoints.GitHub,
+			RedirectURL:  githubRedirectURL,
+			Scopes:       []string{"read:user", "user:email"},
+		}
+	}
+}
+
+// IsAllowedClientRedirect reports whether returnTo is exactly one of the
+// URLs operators configured via ALLOWED_CLIENT_REDIRECTS. Login's
+// return_to query param is attacker-controlled (it's read before the user
+// authenticates), so callers must reject anything not on this allowlist
+// rather than redirecting to it with a freshly issued session JWT attached.
+func IsAllowedClientRedirect(returnTo string) bool {
+	for _, allowed := range ClientRedirectAllowlist {
+		if returnTo == allowed {
+			return true
+		}
+	}
+	return false
 }
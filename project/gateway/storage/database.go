@@ -1,7 +1,7 @@
 package storage
 
 import (
-	"log"
+	"os"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -17,17 +17,20 @@ func InitDB(dsn string) {
 		driver = postgres.Open(dsn)
 	} else {
 		// Error and exit if no supported database is found
-		log.Fatalf("unsupported or missing DATABASE_DSN: %s", dsn)
+		Logger.Error("unsupported or missing DATABASE_DSN", "dsn", dsn)
+		os.Exit(1)
 	}
 
 	// Open the database connection with the selected driver
 	var err error
 	DB, err = gorm.Open(driver, &gorm.Config{})
 	if err != nil {
-		log.Fatalf("failed to open db: %v", err)
+		Logger.Error("failed to open db", "err", err)
+		os.Exit(1)
 	}
 	//! Auto-migration is terrible for production use cases.
 	// if err := db.AutoMigrate(&User{}); err != nil {
-	// 	log.Fatalf("migrate: %v", err)
+	// 	Logger.Error("migrate", "err", err)
+	// 	os.Exit(1)
 	// }
 }
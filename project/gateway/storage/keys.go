@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"gateway/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const signingKeyBits = 2048
+
+// ActiveSigningKey returns the current signing key, generating one on first
+// use so a fresh deployment doesn't need a manual provisioning step.
+func ActiveSigningKey() (*models.SigningKey, error) {
+	var key models.SigningKey
+	err := DB.Where("retired_at IS NULL").Order("created_at desc").First(&key).Error
+	if err == nil {
+		return &key, nil
+	}
+	return GenerateSigningKey()
+}
+
+// GenerateSigningKey creates and persists a new RSA signing key, leaving any
+// previously active key in place so in-flight tokens keep verifying via JWKS.
+func GenerateSigningKey() (*models.SigningKey, error) {
+	priv, err := rsa.GenerateKey(rand.Reader, signingKeyBits)
+	if err != nil {
+		return nil, fmt.Errorf("generate signing key: %w", err)
+	}
+
+	privPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(priv),
+	})
+	pubBytes, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("marshal public key: %w", err)
+	}
+	pubPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "PUBLIC KEY",
+		Bytes: pubBytes,
+	})
+
+	key := &models.SigningKey{
+		Kid:        uuid.New().String(),
+		PrivatePEM: string(privPEM),
+		PublicPEM:  string(pubPEM),
+		CreatedAt:  time.Now(),
+	}
+	if err := DB.Create(key).Error; err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RetireSigningKey marks a key as no longer used for minting new tokens. It
+// stays in the JWKS response until the caller removes it once all tokens
+// signed with it have expired.
+func RetireSigningKey(kid string) error {
+	now := time.Now()
+	return DB.Model(&models.SigningKey{}).Where("kid = ? AND retired_at IS NULL", kid).
+		Update("retired_at", &now).Error
+}
+
+// SigningKeyByKid looks up a key (active or retired) for JWT verification.
+func SigningKeyByKid(kid string) (*models.SigningKey, error) {
+	var key models.SigningKey
+	if err := DB.Where("kid = ?", kid).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// AllSigningKeys returns every key that should still be published in the
+// JWKS document (i.e. anything a still-valid token could reference).
+func AllSigningKeys() ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	if err := DB.Find(&keys).Error; err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// ParseRSAPrivateKey decodes the PEM-encoded private key stored on a SigningKey.
+func ParseRSAPrivateKey(key *models.SigningKey) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(key.PrivatePEM))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM for signing key %s", key.Kid)
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
+
+// ParseRSAPublicKey decodes the PEM-encoded public key stored on a SigningKey.
+func ParseRSAPublicKey(key *models.SigningKey) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(key.PublicPEM))
+	if block == nil {
+		return nil, fmt.Errorf("invalid PEM for signing key %s", key.Kid)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an RSA public key", key.Kid)
+	}
+	return rsaPub, nil
+}
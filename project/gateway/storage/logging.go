@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Logger is the package-level structured logger the whole gateway logs
+// through, configured by InitLogger from LOG_FORMAT (json|text) and
+// LOG_LEVEL (debug|info|warn|error) env vars. It defaults to a plain text
+// logger on stderr so anything logged before InitLogger runs still goes
+// somewhere sane.
+var Logger = slog.New(&redactingHandler{next: slog.NewTextHandler(os.Stderr, nil)})
+
+// InitLogger configures Logger from the environment and makes it slog's
+// package-level default too, so code that calls slog.Info/slog.ErrorContext
+// directly picks up the same level and format.
+func InitLogger() {
+	handlerOpts := &slog.HandlerOptions{Level: parseLogLevel(os.Getenv("LOG_LEVEL"))}
+
+	var handler slog.Handler
+	if os.Getenv("LOG_FORMAT") == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, handlerOpts)
+	}
+
+	Logger = slog.New(&redactingHandler{next: handler})
+	slog.SetDefault(Logger)
+}
+
+// redactedAttrKeys are log attribute keys whose value is replaced with
+// "REDACTED" before reaching the underlying handler, so an OAuth exchange
+// log line can carry "access_token" or "refresh_token" attributes without
+// ever writing a live credential to the log. Other keys, "err" included,
+// pass through unchanged.
+var redactedAttrKeys = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+}
+
+// redactingHandler wraps another slog.Handler, scrubbing sensitive attribute
+// values out of every record (and every attribute attached via With) before
+// handing it off.
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	if redactedAttrKeys[a.Key] {
+		return slog.String(a.Key, "REDACTED")
+	}
+	return a
+}
+
+func parseLogLevel(raw string) slog.Level {
+	switch strings.ToLower(raw) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
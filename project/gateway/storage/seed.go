@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"gateway/models"
+
+	"gorm.io/gorm"
+)
+
+// SeedAdminRoles grants the "admin" role to every AdminDiscordIDs entry
+// that has a matching AuthenticationMethodDiscord, so the first operator
+// can reach the /admin routes without anyone hand-editing the database.
+// It's a no-op once those grants already exist, so it's safe to run on
+// every startup.
+func SeedAdminRoles() {
+	if len(AdminDiscordIDs) == 0 {
+		return
+	}
+
+	var adminRole models.Role
+	if err := DB.Where("name = ?", "admin").FirstOrCreate(&adminRole, models.Role{Name: "admin"}).Error; err != nil {
+		Logger.Error("failed to ensure admin role", "err", err)
+		return
+	}
+
+	for _, discordID := range AdminDiscordIDs {
+		var method models.AuthenticationMethodDiscord
+		if err := DB.Where("discord_id = ?", discordID).First(&method).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				Logger.Error("failed to look up admin discord id", "discord_id", discordID, "err", err)
+			}
+			continue
+		}
+
+		userRole := models.UserRole{UserID: method.UserID, RoleID: adminRole.ID}
+		if err := DB.Where(userRole).FirstOrCreate(&userRole).Error; err != nil {
+			Logger.Error("failed to grant admin role", "user_id", method.UserID, "err", err)
+		}
+	}
+}